@@ -11,7 +11,11 @@ import (
 	"github.com/Shodocan/UserService/internal/database"
 	"github.com/Shodocan/UserService/internal/database/mongo"
 	"github.com/Shodocan/UserService/internal/database/mongoredis"
+	"github.com/Shodocan/UserService/internal/events"
+	"github.com/Shodocan/UserService/internal/services"
 	"github.com/Shodocan/UserService/internal/web"
+	"github.com/Shodocan/UserService/internal/web/handlers"
+	"github.com/Shodocan/UserService/internal/webhooks"
 	"github.com/joho/godotenv"
 )
 
@@ -68,6 +72,20 @@ func main() {
 		}
 	}
 
+	// relay the domain events stored in the outbox
+	broker := events.NewMemoryBroker()
+	sinks := events.SinksFromConfig(config, broker)
+	webhookService := services.NewWebhookServiceMongo(database, config)
+	webhookWorker := webhooks.NewWorker(config, webhookService, logger)
+	if config.WebhooksActive == "true" {
+		sinks = append(sinks, webhooks.NewDispatcher(webhookService))
+		webhookWorker.Start()
+	}
+	relay := events.NewRelay(config, services.NewOutboxServiceMongo(database, config), logger, sinks...)
+	if config.EventsRelayActive == "true" || config.WebhooksActive == "true" {
+		relay.Start()
+	}
+
 	// // create the fiber server
 	server := web.Router(config, database)
 
@@ -79,6 +97,9 @@ func main() {
 	}
 
 	listenShutdown(func() error {
+		handlers.CloseStreams()
+		relay.Stop()
+		webhookWorker.Stop()
 		log.Println(database.Disconnect())
 		return server.Shutdown()
 	})
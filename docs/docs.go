@@ -28,30 +28,84 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/users": {
-            "post": {
-                "description": "Create user",
+        "/admin/cache/stats": {
+            "get": {
+                "description": "Hits, misses, errors, latency and evictions of the cache, counted by the instance serving the request since it started",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Create User",
-                "parameters": [
-                    {
-                        "description": "Create User Request",
-                        "name": "Request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Cache Stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/entity.User"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.CacheStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
                         }
+                    },
+                    "403": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/cache/users/{id}": {
+            "get": {
+                "description": "Show what the cache holds for a user, the user is never loaded",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Inspect User Cache",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "allOf": [
                                 {
@@ -61,7 +115,7 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/entity.User"
+                                            "$ref": "#/definitions/entity.CacheEntry"
                                         }
                                     }
                                 }
@@ -80,7 +134,7 @@ var doc = `{
                             "$ref": "#/definitions/engine.Error"
                         }
                     },
-                    "404": {
+                    "403": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/engine.Error"
@@ -91,20 +145,24 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/engine.Error"
                         }
+                    },
+                    "501": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
                     }
                 }
-            }
-        },
-        "/users/password/{id}": {
-            "post": {
-                "description": "Validate Password",
+            },
+            "delete": {
+                "description": "Drop the cached user, on every instance, and the cached queries of users",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "ValidatePassword",
+                "summary": "Evict User Cache",
                 "parameters": [
                     {
                         "type": "string",
@@ -112,15 +170,6 @@ var doc = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Validate Password Request",
-                        "name": "Request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.ValidatePassword"
-                        }
                     }
                 ],
                 "responses": {
@@ -154,7 +203,7 @@ var doc = `{
                             "$ref": "#/definitions/engine.Error"
                         }
                     },
-                    "404": {
+                    "403": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/engine.Error"
@@ -165,29 +214,33 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/engine.Error"
                         }
+                    },
+                    "501": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
                     }
                 }
             }
         },
-        "/users/search": {
-            "post": {
-                "description": "Search users",
+        "/admin/cache/{namespace}": {
+            "delete": {
+                "description": "Drop every cached document and query of a namespace, on every instance",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Search Users",
+                "summary": "Flush Cache",
                 "parameters": [
                     {
-                        "description": "Search Users Request",
-                        "name": "Request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/requests.SearchUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Namespace",
+                        "name": "namespace",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -196,16 +249,13 @@ var doc = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/engine.PaginationResponse"
+                                    "$ref": "#/definitions/engine.Response"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/entity.User"
-                                            }
+                                            "$ref": "#/definitions/handlers.CacheFlush"
                                         }
                                     }
                                 }
@@ -224,7 +274,7 @@ var doc = `{
                             "$ref": "#/definitions/engine.Error"
                         }
                     },
-                    "404": {
+                    "403": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/engine.Error"
@@ -235,27 +285,35 @@ var doc = `{
                         "schema": {
                             "$ref": "#/definitions/engine.Error"
                         }
+                    },
+                    "501": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
                     }
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
-                "description": "Find user",
+        "/audit/search": {
+            "post": {
+                "description": "Search the audit events of all users, newest first",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Find User",
+                "summary": "Search Audit",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Search Audit Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SearchAuditRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -264,13 +322,16 @@ var doc = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/engine.Response"
+                                    "$ref": "#/definitions/engine.PaginationResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/entity.User"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.AuditEvent"
+                                            }
                                         }
                                     }
                                 }
@@ -302,32 +363,70 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "update user attributes",
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "List users matching a filter expression, like age\u003e=18 and (email~\"@corp.com\" or not address exists).\nOperators are =, !=, \u003e, \u003e=, \u003c, \u003c=, ~, prefix, contains, startsWith, endsWith, iequals, in [\"a\", \"b\"], nin [...] and exists [true|false], combined with and, or, not and parentheses.\nicontains, istartsWith and iendsWith ignore the case. regex takes a raw regular expression and requires a privileged API token",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "PartialUpdate User",
+                "summary": "List Users",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter expression",
+                        "name": "q",
+                        "in": "query"
                     },
                     {
-                        "description": "Update User Request",
-                        "name": "Request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/entity.User"
-                        }
+                        "type": "string",
+                        "description": "Words searched in the name, email and address, sorted by score unless sorted otherwise",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-name,age",
+                        "description": "Sort fields, - for descending, score sorts a text search by relevance",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The next or prev cursor of a previous page, replaces page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Skip counting the users, total and pages are -1",
+                        "name": "skipTotal",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id,name,email",
+                        "description": "The only fields returned, of id, name, age, email and address",
+                        "name": "fields",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -336,17 +435,26 @@ var doc = `{
                         "schema": {
                             "allOf": [
                                 {
-                                    "$ref": "#/definitions/engine.Response"
+                                    "$ref": "#/definitions/engine.PaginationResponse"
                                 },
                                 {
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/entity.User"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.User"
+                                            }
                                         }
                                     }
                                 }
                             ]
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "The first, prev, next and last pages"
+                            }
                         }
                     },
                     "400": {
@@ -361,6 +469,12 @@ var doc = `{
                             "$ref": "#/definitions/engine.Error"
                         }
                     },
+                    "403": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
                     "404": {
                         "description": "Bad Request",
                         "schema": {
@@ -376,24 +490,17 @@ var doc = `{
                 }
             },
             "post": {
-                "description": "update user",
+                "description": "Create user",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Update User",
+                "summary": "Create User",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update User Request",
+                        "description": "Create User Request",
                         "name": "Request",
                         "in": "body",
                         "required": true,
@@ -403,8 +510,8 @@ var doc = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -446,23 +553,27 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "update user attributes",
+            }
+        },
+        "/users/aggregate": {
+            "post": {
+                "description": "Count the users matching the same filters, query and text as a search by groupBy: age brackets, the email domain, the city, the last comma separated part of the address, or the UTC day or month of creation.\nAge brackets start at each of buckets, users without a key are counted in unknown. Dates are listed in order, other groups the largest first, at most 100 of them",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Delete User",
+                "summary": "Aggregate Users",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Aggregate Users Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.AggregateUsersRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -477,7 +588,10 @@ var doc = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "string"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.UserGroup"
+                                            }
                                         }
                                     }
                                 }
@@ -496,6 +610,12 @@ var doc = `{
                             "$ref": "#/definitions/engine.Error"
                         }
                     },
+                    "403": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
                     "404": {
                         "description": "Bad Request",
                         "schema": {
@@ -510,28 +630,1167 @@ var doc = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "engine.Error": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "extra": {
-                    "type": "object",
-                    "additionalProperties": true
-                },
-                "meta": {
-                    "$ref": "#/definitions/engine.MetaData"
-                }
-            }
         },
-        "engine.MetaData": {
-            "type": "object",
-            "properties": {
-                "message": {
+        "/users/events": {
+            "get": {
+                "description": "Server-Sent Events stream of user lifecycle events, send Last-Event-ID to resume after the last received event",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "User Events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated event types",
+                        "name": "types",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.UserEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password/{id}": {
+            "post": {
+                "description": "Validate Password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "ValidatePassword",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Validate Password Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.ValidatePassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "post": {
+                "description": "Search users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Search Users",
+                "parameters": [
+                    {
+                        "description": "Search Users Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/requests.SearchUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.User"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search/fuzzy": {
+            "get": {
+                "description": "Look users up by a misspelled name or email. Users are ranked by the similarity of their name or email to q, from 0 to 1 in score, the best of the trigram and the edit distance similarity",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Fuzzy Search Users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Name or email, misspelled or not",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "default": 0.3,
+                        "description": "The least similarity of the users found, from 0 to 1",
+                        "name": "threshold",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "The most users found",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.User"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Find user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Find User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "id,name,email",
+                        "description": "The only fields returned, of id, name, age, email and address",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update user attributes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "PartialUpdate User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update User Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "update user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update User Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "update user attributes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/audit": {
+            "get": {
+                "description": "List the audit events of a user, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "User Audit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.AuditEvent"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "List webhook subscriptions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List Webhooks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.WebhookSubscription"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe an URL to user events, payloads are signed with the returned secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create Webhook",
+                "parameters": [
+                    {
+                        "description": "Create Webhook Request",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/entity.WebhookSubscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.WebhookSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/dead-letters": {
+            "get": {
+                "description": "Deliveries that exhausted their attempts, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Webhook Dead Letters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.WebhookDelivery"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Find webhook subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Find Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.WebhookSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove webhook subscription",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Delivery history of a webhook subscription, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Webhook Deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.PaginationResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/entity.WebhookDelivery"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "description": "Send a delivery again right away",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Redeliver Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/engine.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/entity.WebhookDelivery"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/engine.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "engine.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "extra": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "meta": {
+                    "$ref": "#/definitions/engine.MetaData"
+                }
+            }
+        },
+        "engine.MetaData": {
+            "type": "object",
+            "properties": {
+                "message": {
                     "type": "string"
                 },
                 "type": {
@@ -547,12 +1806,31 @@ var doc = `{
         "engine.Pagination": {
             "type": "object",
             "properties": {
-                "pageSize": {
+                "hasNext": {
+                    "type": "boolean"
+                },
+                "hasPrev": {
+                    "type": "boolean"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "next": {
+                    "description": "Next and Prev are cursors for the pages around this one, empty when there is no such page",
+                    "type": "string"
+                },
+                "page": {
                     "type": "integer"
                 },
                 "pages": {
                     "type": "integer"
                 },
+                "prev": {
+                    "type": "string"
+                },
+                "returned": {
+                    "type": "integer"
+                },
                 "total": {
                     "type": "integer"
                 }
@@ -583,6 +1861,128 @@ var doc = `{
                 }
             }
         },
+        "entity.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "partial_update",
+                        "delete",
+                        "password_validation",
+                        "password_change"
+                    ]
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "client": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "prevHash": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.CacheEntry": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "type": "boolean"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "freshUntil": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "local": {
+                    "type": "boolean"
+                },
+                "missing": {
+                    "description": "Missing records a cached not found",
+                    "type": "boolean"
+                }
+            }
+        },
+        "entity.CacheOperationStats": {
+            "type": "object",
+            "properties": {
+                "averageLatencyMs": {
+                    "type": "number"
+                },
+                "errors": {
+                    "description": "Errors are reads redis failed, they are served by the database",
+                    "type": "integer"
+                },
+                "hitRatio": {
+                    "description": "HitRatio counts stale hits as hits",
+                    "type": "number"
+                },
+                "hits": {
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "staleHits": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.CacheStats": {
+            "type": "object",
+            "properties": {
+                "evictions": {
+                    "description": "Evictions by namespace",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "localEntries": {
+                    "type": "integer"
+                },
+                "operations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/entity.CacheOperationStats"
+                    }
+                }
+            }
+        },
         "entity.User": {
             "type": "object",
             "properties": {
@@ -603,6 +2003,42 @@ var doc = `{
                 },
                 "password": {
                     "type": "string"
+                },
+                "score": {
+                    "description": "Score is the relevance of the user to a text or fuzzy search, it is only set by those searches",
+                    "type": "number"
+                }
+            }
+        },
+        "entity.UserEvent": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "user.created",
+                        "user.updated",
+                        "user.deleted",
+                        "user.password_changed"
+                    ]
+                },
+                "user": {
+                    "$ref": "#/definitions/entity.User"
+                },
+                "userId": {
+                    "type": "string"
                 }
             }
         },
@@ -618,11 +2054,28 @@ var doc = `{
                         "address"
                     ]
                 },
+                "ignoreCase": {
+                    "type": "boolean"
+                },
                 "operator": {
                     "type": "string",
                     "enum": [
                         "=",
-                        "~"
+                        "~",
+                        "!=",
+                        "\u003e",
+                        "\u003e=",
+                        "\u003c",
+                        "\u003c=",
+                        "in",
+                        "nin",
+                        "exists",
+                        "prefix",
+                        "contains",
+                        "startsWith",
+                        "endsWith",
+                        "iequals",
+                        "regex"
                     ]
                 },
                 "value": {
@@ -630,9 +2083,217 @@ var doc = `{
                 }
             }
         },
+        "entity.UserGroup": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "key": {
+                    "type": "string",
+                    "example": "18-24"
+                }
+            }
+        },
+        "entity.UserQuery": {
+            "type": "object",
+            "properties": {
+                "and": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.UserQuery"
+                    }
+                },
+                "filter": {
+                    "$ref": "#/definitions/entity.UserFilter"
+                },
+                "not": {
+                    "$ref": "#/definitions/entity.UserQuery"
+                },
+                "or": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.UserQuery"
+                    }
+                }
+            }
+        },
+        "entity.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deliveredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "eventType": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "type": "string"
+                },
+                "lastStatusCode": {
+                    "type": "integer"
+                },
+                "nextAttemptAt": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "delivered",
+                        "dead"
+                    ]
+                },
+                "subscriptionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/users"
+                }
+            }
+        },
+        "handlers.CacheFlush": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "type": "integer"
+                },
+                "namespace": {
+                    "type": "string"
+                }
+            }
+        },
+        "requests.AggregateUsersRequest": {
+            "type": "object",
+            "properties": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        0,
+                        18,
+                        30,
+                        50
+                    ]
+                },
+                "filters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.UserFilter"
+                    }
+                },
+                "groupBy": {
+                    "type": "string",
+                    "enum": [
+                        "age",
+                        "emailDomain",
+                        "city",
+                        "createdDay",
+                        "createdMonth"
+                    ],
+                    "example": "age"
+                },
+                "query": {
+                    "$ref": "#/definitions/entity.UserQuery"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "skywalker"
+                }
+            }
+        },
+        "requests.SearchAuditRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "partial_update",
+                        "delete",
+                        "password_validation",
+                        "password_change"
+                    ]
+                },
+                "actor": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
         "requests.SearchUserRequest": {
             "type": "object",
             "properties": {
+                "cursor": {
+                    "type": "string"
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "id",
+                        "name",
+                        "email"
+                    ]
+                },
                 "filters": {
                     "type": "array",
                     "items": {
@@ -647,6 +2308,12 @@ var doc = `{
                     "type": "integer",
                     "example": 1
                 },
+                "query": {
+                    "$ref": "#/definitions/entity.UserQuery"
+                },
+                "skipTotal": {
+                    "type": "boolean"
+                },
                 "sort": {
                     "type": "array",
                     "items": {
@@ -656,6 +2323,10 @@ var doc = `{
                         "-name",
                         "age"
                     ]
+                },
+                "text": {
+                    "type": "string",
+                    "example": "skywalker"
                 }
             }
         },
@@ -3,8 +3,6 @@ module github.com/Shodocan/UserService
 go 1.16
 
 require (
-	github.com/KromDaniel/jonson v0.0.0-20180630143114-d2f9c3c389db // indirect
-	github.com/KromDaniel/rejonson v0.0.0
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751
 	github.com/arsmn/fiber-swagger/v2 v2.6.0
 	github.com/cpuguy83/go-md2man/v2 v2.0.0 // indirect
@@ -24,9 +22,11 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/swaggo/swag v1.7.0
 	github.com/valyala/fasthttp v1.23.0 // indirect
+	github.com/vmihailenco/msgpack/v4 v4.3.12
 	go.mongodb.org/mongo-driver v1.5.1
 	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2
 	golang.org/x/net v0.0.0-20210331212208-0fccb6fa2b5c // indirect
+	golang.org/x/sync v0.0.0-20201020160332-67f06af15bc9
 	golang.org/x/sys v0.0.0-20210403161142-5e06dd20ab57 // indirect
 	golang.org/x/text v0.3.6 // indirect
 	gopkg.in/mgo.v2 v2.0.0-20190816093944-a6b53ec6cb22
@@ -1,18 +1,54 @@
 package engine
 
-import "math"
+// Uncounted is the Total and Pages of a listing whose count was skipped
+const Uncounted = -1
 
+// Pagination describes a page of a listing. Page is 0 for the pages read through a cursor
 type Pagination struct {
-	Pages    int `json:"pages"`
-	Total    int `json:"total"`
-	PageSize int `json:"pageSize"`
+	Page     int  `json:"page,omitempty"`
+	Limit    int  `json:"limit"`
+	Returned int  `json:"returned"`
+	Total    int  `json:"total"`
+	Pages    int  `json:"pages"`
+	HasNext  bool `json:"hasNext"`
+	HasPrev  bool `json:"hasPrev"`
+	// Next and Prev are cursors for the pages around this one, empty when there is no such page
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
 }
 
-func NewPagination(total, size, currentLimit int) Pagination {
-	pag := Pagination{
+// NewPagination describes the page of a listing of total rows, returned is the number of rows in the page
+func NewPagination(total, returned, page, limit int) Pagination {
+	pages := 0
+	if limit > 0 {
+		pages = (total + limit - 1) / limit
+	}
+	return Pagination{
+		Page:     page,
+		Limit:    limit,
+		Returned: returned,
 		Total:    total,
-		PageSize: size,
-		Pages:    int(math.Ceil(float64(total) / float64(currentLimit))),
+		Pages:    pages,
+		HasNext:  page*limit < total,
+		HasPrev:  page > 1,
+	}
+}
+
+// NewUncountedPagination describes a page without counting the listing, more tells
+// whether there are rows after the page
+func NewUncountedPagination(returned, page, limit int, more bool) Pagination {
+	return Pagination{
+		Page:     page,
+		Limit:    limit,
+		Returned: returned,
+		Total:    Uncounted,
+		Pages:    Uncounted,
+		HasNext:  more,
+		HasPrev:  page > 1,
 	}
-	return pag
+}
+
+// Counted reports whether Total and Pages are known
+func (p Pagination) Counted() bool {
+	return p.Total != Uncounted
 }
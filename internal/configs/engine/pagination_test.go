@@ -0,0 +1,26 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPagination(t *testing.T) {
+	pagination := NewPagination(45, 20, 2, 20)
+	assert.Equal(t, Pagination{Page: 2, Limit: 20, Returned: 20, Total: 45, Pages: 3, HasNext: true, HasPrev: true}, pagination)
+
+	pagination = NewPagination(45, 5, 3, 20)
+	assert.False(t, pagination.HasNext, "the third page is the last")
+
+	pagination = NewPagination(10, 0, 1, 0)
+	assert.Equal(t, 0, pagination.Pages, "a zero limit must not divide by zero")
+}
+
+func TestNewUncountedPagination(t *testing.T) {
+	pagination := NewUncountedPagination(20, 1, 20, true)
+	assert.False(t, pagination.Counted())
+	assert.Equal(t, Uncounted, pagination.Pages)
+	assert.True(t, pagination.HasNext)
+	assert.False(t, pagination.HasPrev)
+}
@@ -7,22 +7,66 @@ import (
 )
 
 type EnvVarConfig struct {
-	Port                  string `envconfig:"port" default:"8080"`
-	APIToken              string `envconfig:"api_token" default:"e81384e6-2b68-4d40-b19e-dd585132baa9"`
-	AllowOrigins          string `envconfig:"allowed_origins" default:"localhost"`
-	MongoDBHost           string `envconfig:"mongodb_host" default:"mongodb"`
-	MongoDBPort           string `envconfig:"mongodb_port" default:"27017"`
-	MongoDBDatabase       string `envconfig:"mongodb_database" default:"user"`
-	MongoDBDefaultTimeout string `envconfig:"mongodb_default_timeout" default:"10s"`
-	MongoDBAdminUsername  string `envconfig:"mongodb_adminusername" required:"true"`
-	MongodbAdminPassword  string `envconfig:"mongodb_adminpassword" required:"true"`
-	RedisDBActive         string `envconfig:"redisdb_active" default:"false"`
-	RedisDBHost           string `envconfig:"redisdb_host" default:""`
-	RedisDBPort           string `envconfig:"redisdb_port" default:"6379"`
-	RedisDBPassword       string `envconfig:"redisdb_password" default:"root"`
-	RedisDBDatabase       string `envconfig:"redisdb_database" default:"1"`
-	RedisDBCacheDuration  string `envconfig:"redisdb_cache_duration" default:"30s"`
-	RedisDBDefaultTimeout string `envconfig:"redisdb_default_timeout" default:"3s"`
+	Port                         string `envconfig:"port" default:"8080"`
+	APIToken                     string `envconfig:"api_token" default:"e81384e6-2b68-4d40-b19e-dd585132baa9"`
+	AllowOrigins                 string `envconfig:"allowed_origins" default:"localhost"`
+	PrivilegedAPITokens          string `envconfig:"privileged_api_tokens" default:""`
+	MaxPageSize                  string `envconfig:"max_page_size" default:"100"`
+	FuzzyThreshold               string `envconfig:"fuzzy_threshold" default:"0.3"`
+	FuzzyMaxResults              string `envconfig:"fuzzy_max_results" default:"20"`
+	FuzzyCandidates              string `envconfig:"fuzzy_candidates" default:"500"`
+	MongoDBHost                  string `envconfig:"mongodb_host" default:"mongodb"`
+	MongoDBPort                  string `envconfig:"mongodb_port" default:"27017"`
+	MongoDBDatabase              string `envconfig:"mongodb_database" default:"user"`
+	MongoDBDefaultTimeout        string `envconfig:"mongodb_default_timeout" default:"10s"`
+	MongoDBAdminUsername         string `envconfig:"mongodb_adminusername" required:"true"`
+	MongodbAdminPassword         string `envconfig:"mongodb_adminpassword" required:"true"`
+	MongoDBTransactions          string `envconfig:"mongodb_transactions" default:"true"`
+	RedisDBActive                string `envconfig:"redisdb_active" default:"false"`
+	RedisDBHost                  string `envconfig:"redisdb_host" default:""`
+	RedisDBPort                  string `envconfig:"redisdb_port" default:"6379"`
+	RedisDBPassword              string `envconfig:"redisdb_password" default:"root"`
+	RedisDBDatabase              string `envconfig:"redisdb_database" default:"1"`
+	RedisDBCacheDuration         string `envconfig:"redisdb_cache_duration" default:"30s"`
+	RedisDBMode                  string `envconfig:"redisdb_mode" default:"single"`
+	RedisDBSentinelMaster        string `envconfig:"redisdb_sentinel_master" default:""`
+	RedisDBSentinelAddrs         string `envconfig:"redisdb_sentinel_addrs" default:""`
+	RedisDBClusterAddrs          string `envconfig:"redisdb_cluster_addrs" default:""`
+	RedisDBUsername              string `envconfig:"redisdb_username" default:""`
+	RedisDBTLS                   string `envconfig:"redisdb_tls" default:"false"`
+	RedisDBTLSCACert             string `envconfig:"redisdb_tls_ca_cert" default:""`
+	RedisDBTLSInsecureSkipVerify string `envconfig:"redisdb_tls_insecure_skip_verify" default:"false"`
+	RedisDBBreakerFailures       string `envconfig:"redisdb_breaker_failures" default:"5"`
+	RedisDBBreakerLatency        string `envconfig:"redisdb_breaker_latency" default:"500ms"`
+	RedisDBBreakerCooldown       string `envconfig:"redisdb_breaker_cooldown" default:"10s"`
+	RedisDBKeyPrefix             string `envconfig:"redisdb_key_prefix" default:"userservice"`
+	RedisDBCodec                 string `envconfig:"redisdb_codec" default:"json"`
+	RedisDBDefaultTimeout        string `envconfig:"redisdb_default_timeout" default:"3s"`
+	RedisDBNotFoundDuration      string `envconfig:"redisdb_not_found_duration" default:"5s"`
+	RedisDBStaleWhileRevalidate  string `envconfig:"redisdb_stale_while_revalidate" default:"0s"`
+	RedisDBEarlyRefreshBeta      string `envconfig:"redisdb_early_refresh_beta" default:"0"`
+	RedisDBLocalCacheSize        string `envconfig:"redisdb_local_cache_size" default:"0"`
+	RedisDBLocalCacheTTL         string `envconfig:"redisdb_local_cache_ttl" default:"5s"`
+	RedisDBWatchChanges          string `envconfig:"redisdb_watch_changes" default:"false"`
+	RedisDBWatchNamespaces       string `envconfig:"redisdb_watch_namespaces" default:"users"`
+	EventsRelayActive            string `envconfig:"events_relay_active" default:"false"`
+	EventsRelayInterval          string `envconfig:"events_relay_interval" default:"1s"`
+	EventsRelayBatchSize         string `envconfig:"events_relay_batch_size" default:"100"`
+	EventsMaxAttempts            string `envconfig:"events_max_attempts" default:"10"`
+	EventsRetryBackoff           string `envconfig:"events_retry_backoff" default:"1s"`
+	EventsWebhookURL             string `envconfig:"events_webhook_url" default:""`
+	EventsBroker                 string `envconfig:"events_broker" default:""`
+	EventsBrokerSubject          string `envconfig:"events_broker_subject" default:"events"`
+	WebhooksActive               string `envconfig:"webhooks_active" default:"false"`
+	WebhooksInterval             string `envconfig:"webhooks_interval" default:"1s"`
+	WebhooksBatchSize            string `envconfig:"webhooks_batch_size" default:"50"`
+	WebhooksMaxAttempts          string `envconfig:"webhooks_max_attempts" default:"8"`
+	WebhooksRetryBackoff         string `envconfig:"webhooks_retry_backoff" default:"5s"`
+	WebhooksTimeout              string `envconfig:"webhooks_timeout" default:"10s"`
+	WebhooksAllowPrivate         string `envconfig:"webhooks_allow_private" default:"false"`
+	SSEPollInterval              string `envconfig:"sse_poll_interval" default:"1s"`
+	SSEHeartbeatInterval         string `envconfig:"sse_heartbeat_interval" default:"15s"`
+	SSEBatchSize                 string `envconfig:"sse_batch_size" default:"100"`
 }
 
 func GetEnvConfig() (*EnvVarConfig, error) {
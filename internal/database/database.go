@@ -1,5 +1,10 @@
 package database
 
+import (
+	"context"
+	"time"
+)
+
 type DisconectDB func()
 
 type Database interface {
@@ -11,17 +16,129 @@ type Database interface {
 type MongoDB interface {
 	Database
 	Create(namespace string, data interface{}) (string, error)
-	Find(namespace, idStr string, dst interface{}) error
-	Query(namespace string, filters, sort interface{}, offset, limit int, dst interface{}) error
+	// Find and Query read only the fields listed, every field when there is none
+	Find(namespace, idStr string, dst interface{}, fields ...string) error
+	Query(namespace string, filters, sort interface{}, offset, limit int, dst interface{}, fields ...string) error
 	Total(namespace string, filters interface{}) (int, error)
+	// Aggregate runs an aggregation pipeline, decoding every document it outputs into dst
+	Aggregate(namespace string, pipeline interface{}, dst interface{}) error
 	Update(namespace, idStr string, data interface{}) error
 	Delete(namespace, idStr string) error
+	Transaction(fn func(tx MongoDB) error) error
+}
+
+// TextScore is the field holding the relevance of a document to a text search, the query
+// must list it in its fields to read it and sorting by it sorts by relevance
+const TextScore = "score"
+
+// Collation compares strings by the rules of a locale instead of by their bytes
+type Collation struct {
+	Locale   string
+	Strength int
+}
+
+// CollatedFilter runs a query, a count or an aggregation with a collation, it is accepted
+// wherever a filter or a pipeline is
+type CollatedFilter struct {
+	Filter    interface{}
+	Collation Collation
+}
+
+// TimeLimited stops a query, count or aggregation on the server once it ran for MaxTime,
+// it is accepted wherever a filter or a pipeline is
+type TimeLimited struct {
+	Query   interface{}
+	MaxTime time.Duration
 }
 
 //go:generate mockgen -destination redis_mock.go -package database . RedisDB
 type RedisDB interface {
 	Database
 	Set(idStr string, data interface{}) (string, error)
+	// SetExpire caches data for ttl instead of the default cache duration
+	SetExpire(idStr string, data interface{}, ttl time.Duration) (string, error)
 	Get(idStr string, dst interface{}) error
 	Delete(idStr string) error
+	// Increment atomically adds one to a counter, creating it when missing
+	Increment(key string) (int64, error)
+	// Counter reads a counter, a missing one is zero
+	Counter(key string) (int64, error)
+	// DeleteMatching deletes every key matching a glob pattern, returning how many were deleted
+	DeleteMatching(pattern string) (int64, error)
+	Publish(channel, message string) error
+	// Subscribe calls fn for every message of channel until the returned function is called
+	Subscribe(channel string, fn func(message string)) (func() error, error)
+}
+
+// HealthReporter is implemented by databases whose dependencies have a state
+// worth reporting even when it does not make the database unhealthy
+type HealthReporter interface {
+	Health() map[string]string
+}
+
+// CacheAdmin is implemented by databases whose cache can be managed at runtime
+type CacheAdmin interface {
+	FlushNamespace(namespace string) (int64, error)
+	CacheStats() CacheStats
+	// InspectCache reads the cached document of id without loading it on a miss
+	InspectCache(namespace, idStr string) (CacheEntry, error)
+	// EvictCache drops the cached document of id and the cached queries of its namespace
+	EvictCache(namespace, idStr string) error
+}
+
+// CacheStats counts the cached reads of this instance since it started
+type CacheStats struct {
+	Operations map[string]CacheOperationStats
+	// Evictions by namespace
+	Evictions map[string]int64
+	// LocalEntries is the size of the in-process tier
+	LocalEntries int
+}
+
+type CacheOperationStats struct {
+	Hits      int64
+	StaleHits int64
+	Misses    int64
+	// Errors are reads redis failed, they are served by the database
+	Errors         int64
+	AverageLatency time.Duration
+}
+
+// CacheEntry is the cached state of a document
+type CacheEntry struct {
+	Key    string
+	Cached bool
+	// Local is set when the in-process tier holds the document too
+	Local bool
+	// Missing records a cached not found
+	Missing    bool
+	FreshUntil time.Time
+	Data       []byte
+}
+
+// Cached is implemented by databases that cache another one
+type Cached interface {
+	Source() MongoDB
+}
+
+// SourceOf returns the database behind any cache layer, used when reads must not be stale
+func SourceOf(db MongoDB) MongoDB {
+	if cached, ok := db.(Cached); ok {
+		return SourceOf(cached.Source())
+	}
+	return db
+}
+
+// Change is a write to a namespace reported by the database, whichever client made it
+type Change struct {
+	Operation string
+	Namespace string
+	ID        string
+}
+
+// Watcher is implemented by databases that can stream the changes of a namespace
+type Watcher interface {
+	// Watch calls fn for every change until ctx is done, resuming after the
+	// last change handled by a previous call
+	Watch(ctx context.Context, namespace string, fn func(change Change) error) error
 }
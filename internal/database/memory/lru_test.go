@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewLRU(2, time.Minute)
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	cache.Set("c", 3, 0)
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "b was the least recently used")
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Set("a", 10, 0)
+	value, _ = cache.Get("a")
+	assert.Equal(t, 10, value)
+	assert.Equal(t, 2, cache.Len())
+}
+
+func TestLRUExpires(t *testing.T) {
+	now := time.Now()
+	cache := NewLRU(10, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, time.Second)
+	cache.Set("c", 3, time.Hour)
+
+	now = now.Add(2 * time.Second)
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	_, ok = cache.Get("b")
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("c")
+	assert.False(t, ok, "ttl is capped by the cache ttl")
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestLRUDelete(t *testing.T) {
+	cache := NewLRU(10, time.Minute)
+	cache.Set("a", 1, 0)
+	cache.Set("b", 2, 0)
+
+	cache.Delete("a")
+	_, ok := cache.Get("a")
+	assert.False(t, ok)
+
+	cache.Purge()
+	assert.Equal(t, 0, cache.Len())
+}
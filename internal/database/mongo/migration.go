@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/helpers"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"gopkg.in/mgo.v2/bson"
@@ -32,5 +34,107 @@ func Migrate(config *configs.EnvVarConfig) error {
 			"email": 1, // index in ascending order
 		}, Options: options.Index().SetUnique(true),
 	})
+	if err != nil {
+		return err
+	}
+
+	// name and email are compared with the case insensitive en collation, only an index with the
+	// same collation serves their filters and sorts. The case sensitive ones it replaces are dropped
+	err = dropIndexes(ctx, client.Database(config.MongoDBDatabase).Collection("users"), "name_1__id_1", "email_1__id_1")
+	if err != nil {
+		return err
+	}
+	collation := &options.Collation{Locale: "en", Strength: 2}
+	_, err = client.Database(config.MongoDBDatabase).Collection("users").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: primitive.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("users_name_ci").SetCollation(collation)},
+		{Keys: primitive.D{{Key: "email", Value: 1}, {Key: "_id", Value: 1}}, Options: options.Index().SetName("users_email_ci").SetCollation(collation)},
+	})
+	if err != nil {
+		return err
+	}
+
+	// the text search of the users, names weigh the most. Names aren't words of a language, so
+	// they are neither stemmed nor dropped as stop words
+	_, err = client.Database(config.MongoDBDatabase).Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: primitive.D{{Key: "name", Value: "text"}, {Key: "email", Value: "text"}, {Key: "address", Value: "text"}},
+		Options: options.Index().SetName("users_text").SetDefaultLanguage("none").
+			SetWeights(bson.M{"name": 10, "email": 5, "address": 1}),
+	})
+	if err != nil {
+		return err
+	}
+
+	// the fuzzy search looks users up by the trigrams of their name and email
+	_, err = client.Database(config.MongoDBDatabase).Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.M{"grams": 1},
+	})
+	if err != nil {
+		return err
+	}
+	err = backfillGrams(ctx, client.Database(config.MongoDBDatabase).Collection("users"))
+	if err != nil {
+		return err
+	}
+
+	// the audit chain relies on unique sequences to detect concurrent appends
+	_, err = client.Database(config.MongoDBDatabase).Collection("audit").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"sequence": 1}, Options: options.Index().SetUnique(true)},
+		{Keys: primitive.D{{Key: "userId", Value: 1}, {Key: "sequence", Value: -1}}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Database(config.MongoDBDatabase).Collection("outbox").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: primitive.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	// one delivery per event and subscription, even when the relay publishes an event twice
+	_, err = client.Database(config.MongoDBDatabase).Collection("webhook_deliveries").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: primitive.D{{Key: "subscriptionId", Value: 1}, {Key: "eventId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: primitive.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+	})
 	return err
 }
+
+// dropIndexes drops the indexes named, the ones that don't exist are skipped
+func dropIndexes(ctx context.Context, collection *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		if cmdErr, ok := err.(mongo.CommandError); ok && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillGrams computes the trigrams of the users created before the fuzzy search, of the name
+// and email like the user service does
+func backfillGrams(ctx context.Context, users *mongo.Collection) error {
+	cur, err := users.Find(ctx, bson.M{"grams": bson.M{"$exists": false}}, options.Find().SetProjection(bson.M{"name": 1, "email": 1}))
+	if err != nil {
+		return err
+	}
+	defer cur.Close(ctx)
+	for cur.Next(ctx) {
+		user := struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			Name  string             `bson:"name"`
+			Email string             `bson:"email"`
+		}{}
+		if err = cur.Decode(&user); err != nil {
+			return err
+		}
+		grams := helpers.Trigrams(user.Name + " " + user.Email)
+		if _, err = users.UpdateByID(ctx, user.ID, bson.M{"$set": bson.M{"grams": grams}}); err != nil {
+			return err
+		}
+	}
+	return cur.Err()
+}
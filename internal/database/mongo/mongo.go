@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
@@ -15,6 +16,8 @@ import (
 )
 
 type DB struct {
+	// ctx is set on the copies bound to a transaction session
+	ctx     context.Context
 	timeout time.Duration
 	config  *configs.EnvVarConfig
 	logger  *log.Logger
@@ -37,6 +40,14 @@ func NewDB(config *configs.EnvVarConfig, logger *log.Logger) (database.MongoDB,
 	return db, err
 }
 
+func (db DB) newContext() (context.Context, context.CancelFunc) {
+	parent := db.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithTimeout(parent, db.timeout)
+}
+
 func (db *DB) init() error {
 	var err error
 	config := db.config.GetMongoConnectionString()
@@ -50,7 +61,30 @@ func (db *DB) init() error {
 	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
 	defer cancel()
 	db.logger.Printf("Conectado ao mongo %s %s", db.config.MongoDBHost, db.config.MongoDBDatabase)
-	return db._client.Connect(ctx)
+	if err = db._client.Connect(ctx); err != nil {
+		return err
+	}
+	if db.config.MongoDBTransactions == "true" {
+		return db.checkTransactions(ctx)
+	}
+	return nil
+}
+
+// checkTransactions fails when the server can't run transactions, only replica set
+// members and mongos routers can
+func (db *DB) checkTransactions(ctx context.Context) error {
+	var reply struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := db._client.Database("admin").RunCommand(ctx, primitive.D{{Key: "isMaster", Value: 1}}).Decode(&reply)
+	if err != nil {
+		return err
+	}
+	if reply.SetName == "" && reply.Msg != "isdbgrid" {
+		return errors.New("MongoDB transactions need a replica set, set MONGODB_TRANSACTIONS=false to run on a standalone server without them")
+	}
+	return nil
 }
 
 func (db *DB) Disconnect() error {
@@ -65,10 +99,32 @@ func (db *DB) Ping() error {
 	return db._client.Ping(ctx, readpref.Primary())
 }
 
+// Transaction runs fn with a database whose writes are committed atomically.
+// With MongoDBTransactions off (standalone servers) it is not a transaction: fn
+// runs without a session and the writes it made before failing stay, fn must undo them itself
+func (db *DB) Transaction(fn func(tx database.MongoDB) error) error {
+	if db.config.MongoDBTransactions != "true" || db.ctx != nil {
+		return fn(db)
+	}
+
+	session, err := db._client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.Background())
+
+	_, err = session.WithTransaction(context.Background(), func(sessCtx mongo.SessionContext) (interface{}, error) {
+		tx := *db
+		tx.ctx = sessCtx
+		return nil, fn(&tx)
+	})
+	return err
+}
+
 func (db DB) Create(namespace string, data interface{}) (string, error) {
 	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
 
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	ctx, cancel := db.newContext()
 	defer cancel()
 
 	res, err := collection.InsertOne(ctx, data)
@@ -80,9 +136,9 @@ func (db DB) Create(namespace string, data interface{}) (string, error) {
 	return id.Hex(), nil
 }
 
-func (db DB) Find(namespace, idStr string, dst interface{}) error {
+func (db DB) Find(namespace, idStr string, dst interface{}, fields ...string) error {
 	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	ctx, cancel := db.newContext()
 	defer cancel()
 
 	id, err := primitive.ObjectIDFromHex(idStr)
@@ -90,20 +146,66 @@ func (db DB) Find(namespace, idStr string, dst interface{}) error {
 		return err
 	}
 
-	err = collection.FindOne(ctx, bson.M{"_id": id}).Decode(dst)
+	findOptions := options.FindOne()
+	if len(fields) > 0 {
+		findOptions.SetProjection(projection(fields))
+	}
+	err = collection.FindOne(ctx, bson.M{"_id": id}, findOptions).Decode(dst)
 	return err
 }
 
-func (db DB) Query(namespace string, filters, sort interface{}, offset, limit int, dst interface{}) error {
+// projection includes the fields, _id is always included
+func projection(fields []string) bson.M {
+	included := bson.M{}
+	for _, field := range fields {
+		if field == database.TextScore {
+			included[field] = bson.M{"$meta": "textScore"}
+			continue
+		}
+		included[field] = 1
+	}
+	return included
+}
+
+// unwrap takes the database.CollatedFilter and database.TimeLimited wrappers off a filter or
+// a pipeline, returning a nil collation and no time limit when there are none
+func unwrap(query interface{}) (interface{}, *options.Collation, time.Duration) {
+	var collation *options.Collation
+	var maxTime time.Duration
+	for {
+		switch wrapper := query.(type) {
+		case database.CollatedFilter:
+			collation = &options.Collation{Locale: wrapper.Collation.Locale, Strength: wrapper.Collation.Strength}
+			query = wrapper.Filter
+		case database.TimeLimited:
+			maxTime = wrapper.MaxTime
+			query = wrapper.Query
+		default:
+			return query, collation, maxTime
+		}
+	}
+}
+
+func (db DB) Query(namespace string, filters, sort interface{}, offset, limit int, dst interface{}, fields ...string) error {
 	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
 
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	ctx, cancel := db.newContext()
 	defer cancel()
 
+	filters, collation, maxTime := unwrap(filters)
 	findOptions := options.Find()
 	findOptions.SetLimit(int64(limit))
 	findOptions.SetSkip(int64(offset))
 	findOptions.SetSort(sort)
+	if collation != nil {
+		findOptions.SetCollation(collation)
+	}
+	if maxTime > 0 {
+		findOptions.SetMaxTime(maxTime)
+	}
+	if len(fields) > 0 {
+		findOptions.SetProjection(projection(fields))
+	}
 
 	cur, err := collection.Find(ctx, filters, findOptions)
 	if err != nil {
@@ -115,20 +217,49 @@ func (db DB) Query(namespace string, filters, sort interface{}, offset, limit in
 func (db DB) Total(namespace string, filters interface{}) (int, error) {
 	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
 
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	ctx, cancel := db.newContext()
 	defer cancel()
 
-	total, err := collection.CountDocuments(ctx, filters)
+	filters, collation, maxTime := unwrap(filters)
+	countOptions := options.Count()
+	if collation != nil {
+		countOptions.SetCollation(collation)
+	}
+	if maxTime > 0 {
+		countOptions.SetMaxTime(maxTime)
+	}
+	total, err := collection.CountDocuments(ctx, filters, countOptions)
 	if err != nil {
 		return 0, err
 	}
 	return int(total), nil
 }
 
+func (db DB) Aggregate(namespace string, pipeline interface{}, dst interface{}) error {
+	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
+
+	ctx, cancel := db.newContext()
+	defer cancel()
+
+	pipeline, collation, maxTime := unwrap(pipeline)
+	aggregateOptions := options.Aggregate()
+	if collation != nil {
+		aggregateOptions.SetCollation(collation)
+	}
+	if maxTime > 0 {
+		aggregateOptions.SetMaxTime(maxTime)
+	}
+	cur, err := collection.Aggregate(ctx, pipeline, aggregateOptions)
+	if err != nil {
+		return err
+	}
+	return cur.All(ctx, dst)
+}
+
 func (db DB) Update(namespace, idStr string, data interface{}) error {
 	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
 
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	ctx, cancel := db.newContext()
 	defer cancel()
 
 	id, err := primitive.ObjectIDFromHex(idStr)
@@ -142,7 +273,7 @@ func (db DB) Update(namespace, idStr string, data interface{}) error {
 
 func (db DB) Delete(namespace, idStr string) error {
 	collection := db._client.Database(db.config.MongoDBDatabase).Collection(namespace)
-	ctx, cancel := context.WithTimeout(context.Background(), db.timeout)
+	ctx, cancel := db.newContext()
 	defer cancel()
 
 	id, err := primitive.ObjectIDFromHex(idStr)
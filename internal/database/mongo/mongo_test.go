@@ -4,10 +4,13 @@ import (
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -269,3 +272,23 @@ func TestTotal(t *testing.T) {
 	assert.Nil(t, err, "Should query")
 	assert.Equal(t, total, 2, "Must find 1 cases")
 }
+
+func TestUnwrap(t *testing.T) {
+	filter := bson.M{"name": "Luke"}
+	unwrapped, collation, maxTime := unwrap(filter)
+	assert.Equal(t, filter, unwrapped)
+	assert.Nil(t, collation)
+	assert.Zero(t, maxTime)
+
+	unwrapped, collation, _ = unwrap(database.CollatedFilter{Filter: filter, Collation: database.Collation{Locale: "en", Strength: 3}})
+	assert.Equal(t, filter, unwrapped)
+	assert.Equal(t, &options.Collation{Locale: "en", Strength: 3}, collation)
+
+	unwrapped, collation, maxTime = unwrap(database.TimeLimited{
+		Query:   database.CollatedFilter{Filter: filter, Collation: database.Collation{Locale: "en", Strength: 3}},
+		MaxTime: time.Second,
+	})
+	assert.Equal(t, filter, unwrapped)
+	assert.Equal(t, &options.Collation{Locale: "en", Strength: 3}, collation)
+	assert.Equal(t, time.Second, maxTime)
+}
@@ -0,0 +1,108 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/database"
+	driverbson "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// resumeTokenNamespace keeps the position of each change stream, one document per watched namespace
+const resumeTokenNamespace = "change_stream_tokens"
+
+// change stream errors raised when the resume token is no longer in the oplog
+const (
+	errCodeChangeStreamFatal       = 280
+	errCodeChangeStreamHistoryLost = 286
+)
+
+type dbResumeToken struct {
+	Namespace string         `bson:"_id"`
+	Token     driverbson.Raw `bson:"token"`
+	UpdatedAt time.Time      `bson:"updatedAt"`
+}
+
+type dbChangeEvent struct {
+	OperationType string       `bson:"operationType"`
+	DocumentKey   driverbson.M `bson:"documentKey"`
+}
+
+// Watch follows the change stream of a namespace, the stream requires a replica set.
+// The resume token is saved after every change so a restarted watcher continues where
+// the previous one stopped
+func (db *DB) Watch(ctx context.Context, namespace string, fn func(change database.Change) error) error {
+	mongodb := db._client.Database(db.config.MongoDBDatabase)
+	tokens := mongodb.Collection(resumeTokenNamespace)
+
+	token, err := db.resumeToken(ctx, tokens, namespace)
+	if err != nil {
+		return err
+	}
+	streamOptions := options.ChangeStream()
+	if token != nil {
+		streamOptions.SetResumeAfter(token)
+	}
+	stream, err := mongodb.Collection(namespace).Watch(ctx, mongo.Pipeline{}, streamOptions)
+	if token != nil && isHistoryLost(err) {
+		db.logger.Printf("change stream history of %s lost, watching from now", namespace)
+		stream, err = mongodb.Collection(namespace).Watch(ctx, mongo.Pipeline{})
+	}
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var event dbChangeEvent
+		err = stream.Decode(&event)
+		if err != nil {
+			return err
+		}
+		change := database.Change{Operation: event.OperationType, Namespace: namespace}
+		if id, ok := event.DocumentKey["_id"].(primitive.ObjectID); ok {
+			change.ID = id.Hex()
+		}
+		err = fn(change)
+		if err != nil {
+			return err
+		}
+		err = db.saveResumeToken(tokens, namespace, stream.ResumeToken())
+		if err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return stream.Err()
+}
+
+func (db *DB) resumeToken(ctx context.Context, tokens *mongo.Collection, namespace string) (driverbson.Raw, error) {
+	var token dbResumeToken
+	err := tokens.FindOne(ctx, driverbson.M{"_id": namespace}).Decode(&token)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	return token.Token, err
+}
+
+func (db *DB) saveResumeToken(tokens *mongo.Collection, namespace string, token driverbson.Raw) error {
+	ctx, cancel := db.newContext()
+	defer cancel()
+
+	_, err := tokens.ReplaceOne(ctx, driverbson.M{"_id": namespace}, dbResumeToken{
+		Namespace: namespace,
+		Token:     token,
+		UpdatedAt: time.Now().UTC(),
+	}, options.Replace().SetUpsert(true))
+	return err
+}
+
+func isHistoryLost(err error) bool {
+	commandErr, ok := err.(mongo.CommandError)
+	return ok && (commandErr.Code == errCodeChangeStreamHistoryLost || commandErr.Code == errCodeChangeStreamFatal)
+}
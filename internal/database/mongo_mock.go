@@ -33,6 +33,20 @@ func (m *MockMongoDB) EXPECT() *MockMongoDBMockRecorder {
 	return m.recorder
 }
 
+// Aggregate mocks base method.
+func (m *MockMongoDB) Aggregate(arg0 string, arg1, arg2 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Aggregate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Aggregate indicates an expected call of Aggregate.
+func (mr *MockMongoDBMockRecorder) Aggregate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Aggregate", reflect.TypeOf((*MockMongoDB)(nil).Aggregate), arg0, arg1, arg2)
+}
+
 // Create mocks base method.
 func (m *MockMongoDB) Create(arg0 string, arg1 interface{}) (string, error) {
 	m.ctrl.T.Helper()
@@ -77,17 +91,22 @@ func (mr *MockMongoDBMockRecorder) Disconnect() *gomock.Call {
 }
 
 // Find mocks base method.
-func (m *MockMongoDB) Find(arg0, arg1 string, arg2 interface{}) error {
+func (m *MockMongoDB) Find(arg0, arg1 string, arg2 interface{}, arg3 ...string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Find", arg0, arg1, arg2)
+	varargs := []interface{}{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Find", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Find indicates an expected call of Find.
-func (mr *MockMongoDBMockRecorder) Find(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockMongoDBMockRecorder) Find(arg0, arg1, arg2 interface{}, arg3 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMongoDB)(nil).Find), arg0, arg1, arg2)
+	varargs := append([]interface{}{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockMongoDB)(nil).Find), varargs...)
 }
 
 // Ping mocks base method.
@@ -105,17 +124,22 @@ func (mr *MockMongoDBMockRecorder) Ping() *gomock.Call {
 }
 
 // Query mocks base method.
-func (m *MockMongoDB) Query(arg0 string, arg1, arg2 interface{}, arg3, arg4 int, arg5 interface{}) error {
+func (m *MockMongoDB) Query(arg0 string, arg1, arg2 interface{}, arg3, arg4 int, arg5 interface{}, arg6 ...string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Query", arg0, arg1, arg2, arg3, arg4, arg5)
+	varargs := []interface{}{arg0, arg1, arg2, arg3, arg4, arg5}
+	for _, a := range arg6 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Query", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Query indicates an expected call of Query.
-func (mr *MockMongoDBMockRecorder) Query(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+func (mr *MockMongoDBMockRecorder) Query(arg0, arg1, arg2, arg3, arg4, arg5 interface{}, arg6 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMongoDB)(nil).Query), arg0, arg1, arg2, arg3, arg4, arg5)
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3, arg4, arg5}, arg6...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockMongoDB)(nil).Query), varargs...)
 }
 
 // Total mocks base method.
@@ -133,6 +157,20 @@ func (mr *MockMongoDBMockRecorder) Total(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Total", reflect.TypeOf((*MockMongoDB)(nil).Total), arg0, arg1)
 }
 
+// Transaction mocks base method.
+func (m *MockMongoDB) Transaction(arg0 func(MongoDB) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockMongoDBMockRecorder) Transaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockMongoDB)(nil).Transaction), arg0)
+}
+
 // Update mocks base method.
 func (m *MockMongoDB) Update(arg0, arg1 string, arg2 interface{}) error {
 	m.ctrl.T.Helper()
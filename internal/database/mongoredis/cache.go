@@ -0,0 +1,191 @@
+package mongoredis
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	goredis "github.com/go-redis/redis"
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// cacheEntry wraps a cached value with the moment it stops being fresh, the
+// key itself lives longer when stale values may be served
+type cacheEntry struct {
+	Data       json.RawMessage `json:"data"`
+	FreshUntil time.Time       `json:"freshUntil"`
+	// Delta is how long the value took to load, slower loads are refreshed earlier
+	Delta time.Duration `json:"delta"`
+	// Missing records that the document does not exist
+	Missing bool `json:"missing,omitempty"`
+}
+
+func (entry cacheEntry) decode(dst interface{}) error {
+	if entry.Missing {
+		return driver.ErrNoDocuments
+	}
+	return json.Unmarshal(entry.Data, dst)
+}
+
+type cacheOptions struct {
+	fresh time.Duration
+	// stale is how long an expired value may be served while it is refreshed
+	stale time.Duration
+	// beta scales the probabilistic early refresh, zero disables it
+	beta float64
+	// missing is how long a document not found is remembered, zero disables it
+	missing time.Duration
+}
+
+func newCacheOptions(config *configs.EnvVarConfig, logger *log.Logger) cacheOptions {
+	fresh, err := time.ParseDuration(config.RedisDBCacheDuration)
+	if err != nil {
+		logger.Printf("Invalid Redis cache duration %s, using 30s", config.RedisDBCacheDuration)
+		fresh = 30 * time.Second
+	}
+	stale, err := time.ParseDuration(config.RedisDBStaleWhileRevalidate)
+	if err != nil || stale < 0 {
+		logger.Printf("Invalid Redis stale while revalidate %s, using 0s", config.RedisDBStaleWhileRevalidate)
+		stale = 0
+	}
+	beta, err := strconv.ParseFloat(config.RedisDBEarlyRefreshBeta, 64)
+	if err != nil || beta < 0 {
+		logger.Printf("Invalid Redis early refresh beta %s, using 0", config.RedisDBEarlyRefreshBeta)
+		beta = 0
+	}
+	missing, err := time.ParseDuration(config.RedisDBNotFoundDuration)
+	if err != nil || missing < 0 {
+		logger.Printf("Invalid Redis not found duration %s, using 5s", config.RedisDBNotFoundDuration)
+		missing = 5 * time.Second
+	}
+	return cacheOptions{fresh: fresh, stale: stale, beta: beta, missing: missing}
+}
+
+// refreshEarly decides whether a fresh entry is reloaded before it expires, the
+// chance grows as the expiry gets closer so concurrent readers rarely all miss at once
+func (opts cacheOptions) refreshEarly(entry cacheEntry, now time.Time) bool {
+	if opts.beta == 0 {
+		return false
+	}
+	gap := -float64(entry.Delta) * opts.beta * math.Log(rand.Float64())
+	return now.Add(time.Duration(gap)).After(entry.FreshUntil)
+}
+
+// cached reads key of namespace into dst for the operation op, loading it with load on a miss.
+// Concurrent misses of the same key share a single load
+func (db DB) cached(op, namespace, key string, dst interface{}, load func(dst interface{}) error) error {
+	start := time.Now()
+	if value, ok := db.local.get(key); ok {
+		entry := value.(cacheEntry)
+		if start.Before(entry.FreshUntil) {
+			db.metrics.observe(op, resultHit, start)
+			return entry.decode(dst)
+		}
+	}
+
+	epoch := db.local.begin()
+	var entry cacheEntry
+	err := db.redis.Get(key, &entry)
+	result := resultMiss
+	if err != nil && err != goredis.Nil {
+		result = resultError
+	}
+	if err == nil && (len(entry.Data) > 0 || entry.Missing) {
+		now := time.Now()
+		fresh := now.Before(entry.FreshUntil)
+		if fresh {
+			db.local.fill(epoch, key, entry, entry.FreshUntil.Sub(now))
+		}
+		switch {
+		case fresh && !entry.Missing && db.cache.refreshEarly(entry, now):
+			go db.refresh(namespace, key, dst, load)
+			db.metrics.observe(op, resultHit, start)
+			return entry.decode(dst)
+		case fresh:
+			db.metrics.observe(op, resultHit, start)
+			return entry.decode(dst)
+		case db.cache.stale > 0 && !entry.Missing:
+			// the key outlives its freshness only when stale values may be served
+			go db.refresh(namespace, key, dst, load)
+			db.metrics.observe(op, resultStale, start)
+			return entry.decode(dst)
+		}
+	}
+
+	data, err := db.load(namespace, key, reflect.TypeOf(dst).Elem(), load)
+	db.metrics.observe(op, result, start)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func (db DB) refresh(namespace, key string, dst interface{}, load func(dst interface{}) error) {
+	_, err := db.load(namespace, key, reflect.TypeOf(dst).Elem(), load)
+	if err != nil {
+		db.logger.Printf("failed to refresh cache %v", err)
+	}
+}
+
+// load runs once per key at a time, the result is cached and shared as JSON so
+// every caller decodes its own copy
+func (db DB) load(namespace, key string, typ reflect.Type, load func(dst interface{}) error) ([]byte, error) {
+	data, err, _ := db.flights.Do(key, func() (interface{}, error) {
+		dst := reflect.New(typ).Interface()
+		epoch := db.local.begin()
+		// read before loading, so an invalidation racing the load shows as a newer version
+		version, versionErr := db.redis.Counter(db.keys.version(namespace))
+		start := time.Now()
+		err := load(dst)
+		if err == driver.ErrNoDocuments && db.cache.missing > 0 {
+			entry := cacheEntry{Missing: true, FreshUntil: time.Now().Add(db.cache.missing)}
+			// without a stale window, the document must show up as soon as it is created
+			if versionErr == nil {
+				db.store(namespace, key, version, entry, db.cache.missing)
+			}
+			db.local.fill(epoch, key, entry, db.cache.missing)
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(dst)
+		if err != nil {
+			return nil, err
+		}
+		entry := cacheEntry{Data: data, FreshUntil: time.Now().Add(db.cache.fresh), Delta: time.Since(start)}
+		if versionErr == nil {
+			db.store(namespace, key, version, entry, db.cache.fresh+db.cache.stale)
+		}
+		db.local.fill(epoch, key, entry, db.cache.fresh)
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data.([]byte), nil
+}
+
+// store caches a loaded entry on redis, unless the namespace was invalidated since
+// version was read before the load: the value may predate that write so it is
+// deleted again. Invalidations bump the version before deleting, one that bumps it
+// after the check deletes the value itself
+func (db DB) store(namespace, key string, version int64, entry cacheEntry, ttl time.Duration) {
+	_, err := db.redis.SetExpire(key, entry, ttl)
+	if err != nil {
+		db.logger.Println("failed to cache on Redis")
+		return
+	}
+	current, err := db.redis.Counter(db.keys.version(namespace))
+	if err == nil && current == version {
+		return
+	}
+	err = db.redis.Delete(key)
+	if err != nil {
+		db.logger.Printf("failed to drop a cache entry older than its invalidation %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package mongoredis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	driver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// cachedValue makes a redis Get return value, fresh for the given duration
+func cachedValue(value interface{}, fresh time.Duration) func(key string, dst interface{}) error {
+	return func(key string, dst interface{}) error {
+		data, _ := json.Marshal(value)
+		*dst.(*cacheEntry) = cacheEntry{Data: data, FreshUntil: time.Now().Add(fresh)}
+		return nil
+	}
+}
+
+type cachedUser struct {
+	Name string
+}
+
+func TestConcurrentMissesLoadOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	release := make(chan struct{})
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).Return(fmt.Errorf("redis: nil")).Times(5)
+	mongoMock.EXPECT().Find("users", "1", gomock.Any()).DoAndReturn(func(namespace, id string, dst interface{}) error {
+		<-release
+		dst.(*cachedUser).Name = "Walisson"
+		return nil
+	}).Times(1)
+	redisMock.EXPECT().SetExpire("test:users:id:1", gomock.Any(), time.Minute).Return("1", nil).Times(1)
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), nil).Times(2)
+
+	var wg sync.WaitGroup
+	started := sync.WaitGroup{}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		started.Add(1)
+		go func() {
+			defer wg.Done()
+			started.Done()
+			var user cachedUser
+			assert.Nil(t, db.Find("users", "1", &user))
+			assert.Equal(t, "Walisson", user.Name)
+		}()
+	}
+	started.Wait()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func TestStaleValueServedWhileRefreshing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+	db.cache.stale = time.Minute
+
+	refreshed := make(chan struct{})
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).DoAndReturn(cachedValue(cachedUser{Name: "Old"}, -time.Second))
+	mongoMock.EXPECT().Find("users", "1", gomock.Any()).DoAndReturn(func(namespace, id string, dst interface{}) error {
+		dst.(*cachedUser).Name = "New"
+		return nil
+	})
+	redisMock.EXPECT().SetExpire("test:users:id:1", gomock.Any(), 2*time.Minute).DoAndReturn(func(key string, data interface{}, ttl time.Duration) (string, error) {
+		return key, nil
+	})
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), nil)
+	redisMock.EXPECT().Counter("test:users:version").DoAndReturn(func(string) (int64, error) {
+		close(refreshed)
+		return 0, nil
+	})
+
+	var user cachedUser
+	assert.Nil(t, db.Find("users", "1", &user))
+	assert.Equal(t, "Old", user.Name)
+	select {
+	case <-refreshed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stale value not refreshed")
+	}
+}
+
+func TestExpiredValueReloadedWithoutStaleMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).DoAndReturn(cachedValue(cachedUser{Name: "Old"}, -time.Second))
+	mongoMock.EXPECT().Find("users", "1", gomock.Any()).DoAndReturn(func(namespace, id string, dst interface{}) error {
+		dst.(*cachedUser).Name = "New"
+		return nil
+	})
+	redisMock.EXPECT().SetExpire("test:users:id:1", gomock.Any(), time.Minute).Return("1", nil)
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), nil).Times(2)
+
+	var user cachedUser
+	assert.Nil(t, db.Find("users", "1", &user))
+	assert.Equal(t, "New", user.Name)
+}
+
+func TestFindFieldsSkipsCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, _ := newMockedDB(ctrl)
+
+	// no redis call is expected, the whole cached document would carry every field
+	mongoMock.EXPECT().Find("users", "1", gomock.Any(), "_id", "name").DoAndReturn(func(namespace, id string, dst interface{}, fields ...string) error {
+		dst.(*cachedUser).Name = "Walisson"
+		return nil
+	})
+
+	var user cachedUser
+	assert.Nil(t, db.Find("users", "1", &user, "_id", "name"))
+	assert.Equal(t, "Walisson", user.Name)
+}
+
+func TestRefreshEarly(t *testing.T) {
+	entry := cacheEntry{FreshUntil: time.Now().Add(time.Second), Delta: time.Hour}
+	assert.False(t, cacheOptions{}.refreshEarly(entry, time.Now()))
+	assert.True(t, cacheOptions{beta: 1000}.refreshEarly(entry, time.Now()))
+
+	entry.Delta = 0
+	assert.False(t, cacheOptions{beta: 1}.refreshEarly(entry, time.Now()))
+}
+
+func TestNotFoundIsCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+	db.cache.missing = 5 * time.Second
+
+	var missing cacheEntry
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).Return(fmt.Errorf("redis: nil"))
+	mongoMock.EXPECT().Find("users", "1", gomock.Any()).Return(driver.ErrNoDocuments)
+	redisMock.EXPECT().SetExpire("test:users:id:1", gomock.Any(), 5*time.Second).DoAndReturn(func(key string, data interface{}, ttl time.Duration) (string, error) {
+		missing = data.(cacheEntry)
+		return key, nil
+	})
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), nil).Times(2)
+
+	var user cachedUser
+	assert.Equal(t, driver.ErrNoDocuments, db.Find("users", "1", &user))
+	assert.True(t, missing.Missing)
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).DoAndReturn(func(key string, dst interface{}) error {
+		*dst.(*cacheEntry) = missing
+		return nil
+	})
+	assert.Equal(t, driver.ErrNoDocuments, db.Find("users", "1", &user), "served from cache without hitting mongo")
+}
+
+func TestNotFoundCacheDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).Return(fmt.Errorf("redis: nil"))
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), nil)
+	mongoMock.EXPECT().Find("users", "1", gomock.Any()).Return(driver.ErrNoDocuments)
+
+	var user cachedUser
+	assert.Equal(t, driver.ErrNoDocuments, db.Find("users", "1", &user))
+}
+
+func TestLoadRacingAnInvalidationIsDropped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).Return(fmt.Errorf("redis: nil"))
+	gomock.InOrder(
+		redisMock.EXPECT().Counter("test:users:version").Return(int64(4), nil),
+		mongoMock.EXPECT().Find("users", "1", gomock.Any()).DoAndReturn(func(namespace, id string, dst interface{}) error {
+			dst.(*cachedUser).Name = "Old"
+			return nil
+		}),
+		redisMock.EXPECT().SetExpire("test:users:id:1", gomock.Any(), time.Minute).Return("1", nil),
+		// an update was invalidated while the old document was loading
+		redisMock.EXPECT().Counter("test:users:version").Return(int64(5), nil),
+		redisMock.EXPECT().Delete("test:users:id:1").Return(nil),
+	)
+
+	var user cachedUser
+	assert.Nil(t, db.Find("users", "1", &user))
+	assert.Equal(t, "Old", user.Name)
+}
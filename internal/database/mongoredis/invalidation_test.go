@@ -0,0 +1,113 @@
+package mongoredis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/metrics"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sync/singleflight"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func newMockedDB(ctrl *gomock.Controller) (*DB, *database.MockMongoDB, *database.MockRedisDB) {
+	mongoMock := database.NewMockMongoDB(ctrl)
+	redisMock := database.NewMockRedisDB(ctrl)
+	return &DB{
+		mongo:   mongoMock,
+		redis:   redisMock,
+		logger:  configs.NewLog(),
+		cache:   cacheOptions{fresh: time.Minute},
+		flights: &singleflight.Group{},
+		keys:    keyScheme{prefix: "test"},
+		metrics: newCacheMetrics(metrics.NewRegistry()),
+	}, mongoMock, redisMock
+}
+
+func TestWritesInvalidateCachedQueries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+	filter := bson.M{"name": "Walisson"}
+
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(1), nil)
+	redisMock.EXPECT().Get(db.QueryKey("users", 1, filter, nil, -1, -1), gomock.Any()).DoAndReturn(cachedValue(5, time.Minute))
+	total, err := db.Total("users", filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, total)
+
+	mongoMock.EXPECT().Create("users", gomock.Any()).Return("1", nil)
+	redisMock.EXPECT().Delete("test:users:id:1").Return(nil)
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(2), nil)
+	_, err = db.Create("users", bson.M{})
+	assert.Nil(t, err)
+
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(2), nil)
+	redisMock.EXPECT().Get(db.QueryKey("users", 2, filter, nil, -1, -1), gomock.Any()).Return(fmt.Errorf("redis: nil"))
+	mongoMock.EXPECT().Total("users", filter).Return(3, nil)
+	redisMock.EXPECT().SetExpire(db.QueryKey("users", 2, filter, nil, -1, -1), gomock.Any(), time.Minute).Return("", nil)
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(2), nil).Times(2)
+	total, err = db.Total("users", filter)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, total)
+
+	mongoMock.EXPECT().Update("users", "1", gomock.Any()).Return(nil)
+	redisMock.EXPECT().Delete("test:users:id:1").Return(nil)
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(3), nil)
+	assert.Nil(t, db.Update("users", "1", bson.M{}))
+}
+
+func TestQueryWithoutVersionSkipsCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), fmt.Errorf("redis down"))
+	mongoMock.EXPECT().Total("users", nil).Return(2, nil)
+	total, err := db.Total("users", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, total)
+}
+
+func TestTransactionInvalidatesAfterCommit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	committed := false
+	mongoMock.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx database.MongoDB) error) error {
+		err := fn(mongoMock)
+		committed = true
+		return err
+	})
+	mongoMock.EXPECT().Update("users", "1", gomock.Any()).Return(nil)
+	redisMock.EXPECT().Delete("test:users:id:1").DoAndReturn(func(string) error {
+		assert.True(t, committed)
+		return nil
+	})
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(1), nil)
+
+	err := db.Transaction(func(tx database.MongoDB) error {
+		return tx.Update("users", "1", bson.M{})
+	})
+	assert.Nil(t, err)
+}
+
+func TestFlushNamespace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, _, redisMock := newMockedDB(ctrl)
+
+	gomock.InOrder(
+		redisMock.EXPECT().Increment("test:users:version").Return(int64(4), nil),
+		redisMock.EXPECT().DeleteMatching("test:users:id:*").Return(int64(2), nil),
+		redisMock.EXPECT().DeleteMatching("test:users:q:*").Return(int64(3), nil),
+	)
+	deleted, err := db.FlushNamespace("users")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), deleted)
+}
@@ -0,0 +1,50 @@
+package mongoredis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// keyScheme builds the redis keys, they all start with the service prefix and the
+// namespace so services and namespaces sharing a redis database never collide
+//
+//	<prefix>:<namespace>:id:<id>              cached document
+//	<prefix>:<namespace>:q:v<version>:<hash>  cached query or count, hash is a sha256 of the query
+//	<prefix>:<namespace>:version              namespace version, bumped on every write
+type keyScheme struct {
+	prefix string
+}
+
+func (k keyScheme) document(namespace, idStr string) string {
+	return fmt.Sprintf("%s:%s:id:%s", k.prefix, namespace, idStr)
+}
+
+func (k keyScheme) query(namespace string, version int64, query []byte) string {
+	digest := sha256.Sum256(query)
+	return fmt.Sprintf("%s:%s:q:v%d:%s", k.prefix, namespace, version, hex.EncodeToString(digest[:]))
+}
+
+func (k keyScheme) version(namespace string) string {
+	return fmt.Sprintf("%s:%s:version", k.prefix, namespace)
+}
+
+// documents and queries match every cached entry of a namespace, not its version
+func (k keyScheme) documents(namespace string) string {
+	return fmt.Sprintf("%s:%s:id:*", k.prefix, namespace)
+}
+
+func (k keyScheme) queries(namespace string) string {
+	return fmt.Sprintf("%s:%s:q:*", k.prefix, namespace)
+}
+
+// everything matches all keys of a namespace, sent to the local tiers on a flush
+func (k keyScheme) everything(namespace string) string {
+	return fmt.Sprintf("%s:%s:*", k.prefix, namespace)
+}
+
+// channel carries the keys evicted by any instance, so every instance drops
+// them from its local tier
+func (k keyScheme) channel() string {
+	return k.prefix + ":cache:invalidate"
+}
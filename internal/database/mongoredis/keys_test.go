@@ -0,0 +1,25 @@
+package mongoredis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeySchemeSeparatesNamespaces(t *testing.T) {
+	keys := keyScheme{prefix: "userservice"}
+
+	assert.Equal(t, "userservice:users:id:1", keys.document("users", "1"))
+	assert.Equal(t, "userservice:users:version", keys.version("users"))
+	assert.Regexp(t, `^userservice:users:q:v3:[0-9a-f]{64}$`, keys.query("users", 3, []byte(`{}`)))
+	assert.NotEqual(t, keys.query("users", 3, []byte(`{}`)), keys.query("audit", 3, []byte(`{}`)))
+	assert.NotEqual(t, keys.query("users", 3, []byte(`{}`)), keyScheme{prefix: "other"}.query("users", 3, []byte(`{}`)))
+	assert.NotRegexp(t, `^userservice:users:id:`, keys.query("users", 3, []byte(`{}`)), "flushing documents must not match queries")
+}
+
+func TestQueryKeyIncludesFields(t *testing.T) {
+	db := DB{keys: keyScheme{prefix: "test"}}
+	whole := db.QueryKey("users", 1, map[string]interface{}{}, nil, 0, 10)
+	assert.Equal(t, whole, db.QueryKey("users", 1, map[string]interface{}{}, nil, 0, 10))
+	assert.NotEqual(t, whole, db.QueryKey("users", 1, map[string]interface{}{}, nil, 0, 10, "name"), "a sparse page must not be served for a whole one")
+}
@@ -0,0 +1,124 @@
+package mongoredis
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database/memory"
+)
+
+// localCache is the in-process tier in front of redis, a nil localCache is disabled.
+// Values are only stored when no invalidation arrived while they were read, so a
+// slow read can't bring back an entry another instance just evicted
+type localCache struct {
+	lru   *memory.LRU
+	epoch int64
+}
+
+func newLocalCache(config *configs.EnvVarConfig, logger *log.Logger) *localCache {
+	size, err := strconv.Atoi(config.RedisDBLocalCacheSize)
+	if err != nil || size < 0 {
+		logger.Printf("Invalid local cache size %s, using 0", config.RedisDBLocalCacheSize)
+		size = 0
+	}
+	if size == 0 {
+		return nil
+	}
+	ttl, err := time.ParseDuration(config.RedisDBLocalCacheTTL)
+	if err != nil || ttl <= 0 {
+		logger.Printf("Invalid local cache ttl %s, using 5s", config.RedisDBLocalCacheTTL)
+		ttl = 5 * time.Second
+	}
+	return &localCache{lru: memory.NewLRU(size, ttl)}
+}
+
+func (c *localCache) get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+	return c.lru.Get(key)
+}
+
+func (c *localCache) len() int {
+	if c == nil {
+		return 0
+	}
+	return c.lru.Len()
+}
+
+// begin marks the start of a read whose result may be stored with fill
+func (c *localCache) begin() int64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&c.epoch)
+}
+
+func (c *localCache) fill(epoch int64, key string, value interface{}, ttl time.Duration) {
+	if c == nil || atomic.LoadInt64(&c.epoch) != epoch {
+		return
+	}
+	c.lru.Set(key, value, ttl)
+}
+
+// invalidate drops key, a key ending with * drops everything
+func (c *localCache) invalidate(key string) {
+	if c == nil {
+		return
+	}
+	atomic.AddInt64(&c.epoch, 1)
+	if strings.HasSuffix(key, "*") {
+		c.lru.Purge()
+		return
+	}
+	c.lru.Delete(key)
+}
+
+// evictLocal drops the keys of a document and its namespace version from every instance
+func (db DB) evictLocal(namespace, idStr string) {
+	if db.local == nil {
+		return
+	}
+	keys := []string{db.keys.version(namespace)}
+	if idStr != "" {
+		keys = append(keys, db.keys.document(namespace, idStr))
+	}
+	db.publishLocal(keys...)
+}
+
+// purgeLocal drops everything cached by the local tiers of every instance
+func (db DB) purgeLocal(namespace string) {
+	if db.local == nil {
+		return
+	}
+	db.publishLocal(db.keys.everything(namespace))
+}
+
+func (db DB) publishLocal(keys ...string) {
+	for _, key := range keys {
+		db.local.invalidate(key)
+		err := db.redis.Publish(db.keys.channel(), key)
+		if err != nil {
+			db.logger.Printf("failed to publish cache invalidation %v", err)
+		}
+	}
+}
+
+// version reads the namespace version, from the local tier when possible
+func (db DB) version(namespace string) (int64, error) {
+	key := db.keys.version(namespace)
+	if value, ok := db.local.get(key); ok {
+		return value.(int64), nil
+	}
+	epoch := db.local.begin()
+	version, err := db.redis.Counter(key)
+	if err != nil {
+		return 0, err
+	}
+	db.local.fill(epoch, key, version, 0)
+	return version, nil
+}
@@ -0,0 +1,64 @@
+package mongoredis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/database/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestLocalTierServesRepeatedReads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+	db.local = &localCache{lru: memory.NewLRU(10, time.Minute)}
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).DoAndReturn(cachedValue(cachedUser{Name: "Walisson"}, time.Minute)).Times(1)
+	for i := 0; i < 3; i++ {
+		var user cachedUser
+		assert.Nil(t, db.Find("users", "1", &user))
+		assert.Equal(t, "Walisson", user.Name)
+	}
+
+	mongoMock.EXPECT().Update("users", "1", gomock.Any()).Return(nil)
+	redisMock.EXPECT().Delete("test:users:id:1").Return(nil)
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(1), nil)
+	redisMock.EXPECT().Publish("test:cache:invalidate", "test:users:version").Return(nil)
+	redisMock.EXPECT().Publish("test:cache:invalidate", "test:users:id:1").Return(nil)
+	assert.Nil(t, db.Update("users", "1", bson.M{}))
+
+	_, ok := db.local.get("test:users:id:1")
+	assert.False(t, ok, "update must evict the local entry")
+}
+
+func TestLocalInvalidationDiscardsInFlightFill(t *testing.T) {
+	local := &localCache{lru: memory.NewLRU(10, time.Minute)}
+	local.fill(local.begin(), "1", "old", 0)
+
+	epoch := local.begin()
+	local.invalidate("1")
+	local.fill(epoch, "1", "read before the invalidation", 0)
+
+	_, ok := local.get("1")
+	assert.False(t, ok)
+
+	var disabled *localCache
+	disabled.fill(disabled.begin(), "1", "value", 0)
+	_, ok = disabled.get("1")
+	assert.False(t, ok)
+}
+
+func TestLocalInvalidatePatternPurges(t *testing.T) {
+	local := &localCache{lru: memory.NewLRU(10, time.Minute)}
+	local.fill(local.begin(), "test:users:id:1", "value", 0)
+	local.fill(local.begin(), "test:users:version", int64(1), 0)
+
+	local.invalidate("test:users:*")
+	_, ok := local.get("test:users:id:1")
+	assert.False(t, ok)
+	_, ok = local.get("test:users:version")
+	assert.False(t, ok)
+}
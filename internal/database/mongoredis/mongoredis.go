@@ -1,7 +1,6 @@
 package mongoredis
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -10,12 +9,26 @@ import (
 	"github.com/Shodocan/UserService/internal/database"
 	"github.com/Shodocan/UserService/internal/database/mongo"
 	"github.com/Shodocan/UserService/internal/database/redis"
+	"github.com/Shodocan/UserService/internal/metrics"
+	goredis "github.com/go-redis/redis"
+	"golang.org/x/sync/singleflight"
 )
 
 type DB struct {
-	mongo  database.MongoDB
-	redis  database.RedisDB
-	logger *log.Logger
+	mongo   database.MongoDB
+	redis   database.RedisDB
+	logger  *log.Logger
+	watcher *watcher
+	breaker *redis.Breaker
+	keys    keyScheme
+	cache   cacheOptions
+	flights *singleflight.Group
+	local   *localCache
+	metrics *cacheMetrics
+	// unsubscribe stops listening to the invalidations of other instances
+	unsubscribe func() error
+	// pending collects the invalidations of a running transaction
+	pending *[]invalidation
 }
 
 func NewDB(config *configs.EnvVarConfig, logger *log.Logger) (database.MongoDB, error) {
@@ -24,15 +37,56 @@ func NewDB(config *configs.EnvVarConfig, logger *log.Logger) (database.MongoDB,
 		return nil, err
 	}
 
-	redisdb, err := redis.NewDB(config, logger)
+	client, err := redis.NewDB(config, logger)
 	if err != nil {
+		// only an invalid configuration fails here, connections are made on demand
 		logger.Printf("Failed to connect redis :%v", err)
+		mongodb.Disconnect()
+		return nil, err
 	}
+	redisdb := redis.NewBreaker(client, config, logger)
 
-	return &DB{mongo: mongodb, redis: redisdb, logger: logger}, nil
+	db := &DB{
+		mongo:   mongodb,
+		redis:   redisdb,
+		breaker: redisdb,
+		keys:    keyScheme{prefix: config.RedisDBKeyPrefix},
+		logger:  logger,
+		cache:   newCacheOptions(config, logger),
+		flights: &singleflight.Group{},
+		local:   newLocalCache(config, logger),
+		metrics: newCacheMetrics(metrics.Default),
+	}
+	if db.local != nil {
+		db.unsubscribe, err = redisdb.Subscribe(db.keys.channel(), db.local.invalidate)
+		if err != nil {
+			// without invalidations the local tier would serve stale data
+			logger.Printf("Failed to subscribe cache invalidations, local cache disabled :%v", err)
+			db.local = nil
+		}
+	}
+	if config.RedisDBWatchChanges == "true" {
+		source, ok := mongodb.(database.Watcher)
+		if ok {
+			db.watcher = newWatcher(source, db.evict, config.RedisDBWatchNamespaces, logger)
+			db.watcher.start()
+		} else {
+			logger.Println("database does not support watching changes")
+		}
+	}
+	return db, nil
 }
 
 func (db *DB) Disconnect() error {
+	if db.watcher != nil {
+		db.watcher.stop()
+	}
+	if db.unsubscribe != nil {
+		err := db.unsubscribe()
+		if err != nil {
+			db.logger.Printf("failed to unsubscribe cache invalidations %v", err)
+		}
+	}
 	err := db.mongo.Disconnect()
 	redisErr := db.redis.Disconnect()
 
@@ -50,64 +104,100 @@ func (db *DB) Ping() error {
 	return db.mongo.Ping()
 }
 
+// Health reports the redis circuit, an open circuit only makes reads slower as they go to mongo
+func (db *DB) Health() map[string]string {
+	if db.breaker == nil {
+		return map[string]string{}
+	}
+	return map[string]string{"redisCircuit": db.breaker.State().String()}
+}
+
+func (db *DB) Source() database.MongoDB {
+	return db.mongo
+}
+
+// Transaction defers the cache invalidations of fn until the transaction is over,
+// otherwise a concurrent read could cache the old data under the new version
+func (db *DB) Transaction(fn func(tx database.MongoDB) error) error {
+	pending := db.pending
+	if pending == nil {
+		pending = &[]invalidation{}
+	}
+	err := db.mongo.Transaction(func(tx database.MongoDB) error {
+		txDB := *db
+		txDB.mongo = tx
+		txDB.pending = pending
+		return fn(&txDB)
+	})
+	if db.pending != nil {
+		return err
+	}
+	for _, inv := range *pending {
+		evictErr := db.evict(inv.namespace, inv.id)
+		if evictErr != nil {
+			db.logger.Printf("failed to invalidate cache on Redis %v", evictErr)
+		}
+	}
+	return err
+}
+
 func (db DB) Create(namespace string, data interface{}) (string, error) {
 	id, err := db.mongo.Create(namespace, data)
 	if err != nil {
 		return "", err
 	}
+	// evicting the new id drops a not found cached before it existed
+	err = db.invalidate(namespace, id)
+	if err != nil {
+		db.logger.Printf("failed to invalidate cache on Redis %v", err)
+	}
 	return id, nil
 }
 
-func (db DB) Find(namespace, idStr string, dst interface{}) error {
-	err := db.redis.Get(idStr, dst)
-	if err != nil {
-		err := db.mongo.Find(namespace, idStr, dst)
-		if err != nil {
-			return err
-		}
-		_, err = db.redis.Set(idStr, dst)
-		if err != nil {
-			db.logger.Println("failed to cache on Redis")
-		}
+// Find caches whole documents, reads of some fields skip the cache so only those are read
+func (db DB) Find(namespace, idStr string, dst interface{}, fields ...string) error {
+	if db.pending != nil || len(fields) > 0 {
+		// reads inside a transaction may see uncommitted writes, they must not be cached
+		return db.mongo.Find(namespace, idStr, dst, fields...)
 	}
-	return nil
+	return db.cached(opFind, namespace, db.keys.document(namespace, idStr), dst, func(dst interface{}) error {
+		return db.mongo.Find(namespace, idStr, dst)
+	})
 }
 
-func (db DB) Query(namespace string, filters, sort interface{}, offset, limit int, dst interface{}) error {
-	hash := db.QueryHash(namespace, filters, sort, offset, limit)
-
-	err := db.redis.Get(hash, dst)
-	if err != nil {
-		err = db.mongo.Query(namespace, filters, sort, offset, limit, dst)
-		if err != nil {
-			return err
-		}
-		_, err = db.redis.Set(hash, dst)
-		if err != nil {
-			db.logger.Println("failed to cache on Redis")
-		}
+func (db DB) Query(namespace string, filters, sort interface{}, offset, limit int, dst interface{}, fields ...string) error {
+	key, cacheable := db.versionedKey(namespace, filters, sort, offset, limit, fields...)
+	if !cacheable {
+		return db.mongo.Query(namespace, filters, sort, offset, limit, dst, fields...)
 	}
-
-	return nil
+	return db.cached(opQuery, namespace, key, dst, func(dst interface{}) error {
+		return db.mongo.Query(namespace, filters, sort, offset, limit, dst, fields...)
+	})
 }
 
 func (db DB) Total(namespace string, filters interface{}) (int, error) {
-	hash := db.QueryHash(namespace, filters, nil, -1, -1)
+	key, cacheable := db.versionedKey(namespace, filters, nil, -1, -1)
+	if !cacheable {
+		return db.mongo.Total(namespace, filters)
+	}
 
 	var total int
-	err := db.redis.Get(hash, &total)
-	if err != nil {
-		total, err = db.mongo.Total(namespace, filters)
-		if err != nil {
-			return total, err
-		}
-		_, err = db.redis.Set(hash, total)
-		if err != nil {
-			db.logger.Println("failed to cache on Redis")
-		}
-	}
+	err := db.cached(opTotal, namespace, key, &total, func(dst interface{}) error {
+		count, err := db.mongo.Total(namespace, filters)
+		*dst.(*int) = count
+		return err
+	})
+	return total, err
+}
 
-	return total, nil
+func (db DB) Aggregate(namespace string, pipeline interface{}, dst interface{}) error {
+	key, cacheable := db.versionedKey(namespace, Pipeline{Stages: pipeline}, nil, -1, -1)
+	if !cacheable {
+		return db.mongo.Aggregate(namespace, pipeline, dst)
+	}
+	return db.cached(opAggregate, namespace, key, dst, func(dst interface{}) error {
+		return db.mongo.Aggregate(namespace, pipeline, dst)
+	})
 }
 
 func (db DB) Update(namespace, idStr string, data interface{}) error {
@@ -115,7 +205,7 @@ func (db DB) Update(namespace, idStr string, data interface{}) error {
 	if err != nil {
 		return err
 	}
-	err = db.redis.Delete(idStr)
+	err = db.invalidate(namespace, idStr)
 	if err != nil {
 		db.logger.Println("failed to cache on Redis")
 	}
@@ -124,38 +214,130 @@ func (db DB) Update(namespace, idStr string, data interface{}) error {
 
 func (db DB) Delete(namespace, idStr string) error {
 	err := db.mongo.Delete(namespace, idStr)
-	redisErr := db.redis.Delete(idStr)
+	if err != nil {
+		return err
+	}
+	// an unavailable redis must not fail the delete
+	err = db.invalidate(namespace, idStr)
+	if err != nil {
+		db.logger.Printf("failed to invalidate cache on Redis %v", err)
+	}
+	return nil
+}
 
-	if db.redis.Ping() != nil {
-		// if redis is unvaliable must ignore delete errors
-		redisErr = nil
+// invalidation is a cache eviction waiting for its transaction to finish
+type invalidation struct {
+	namespace string
+	id        string
+}
+
+// invalidate evicts the cached document, when id is set, and every cached query of the namespace
+func (db DB) invalidate(namespace, idStr string) error {
+	if db.pending != nil {
+		*db.pending = append(*db.pending, invalidation{namespace: namespace, id: idStr})
+		return nil
 	}
+	return db.evict(namespace, idStr)
+}
 
-	if err != nil || redisErr != nil {
-		return fmt.Errorf("error deleting redis: %v, mongo:%v", redisErr, err)
+func (db DB) evict(namespace, idStr string) error {
+	defer db.evictLocal(namespace, idStr)
+	db.metrics.evicted(namespace)
+	// bumping the version orphans every cached query of the namespace, and runs first
+	// so a load racing the delete drops what it cached. The delete runs even when the
+	// bump failed so both are kept for replay while redis is unavailable
+	_, err := db.redis.Increment(db.keys.version(namespace))
+	if idStr != "" {
+		if deleteErr := db.redis.Delete(db.keys.document(namespace, idStr)); err == nil {
+			err = deleteErr
+		}
 	}
-	return nil
+	return err
+}
+
+// FlushNamespace drops every cached document and query of the namespace, on every instance
+func (db *DB) FlushNamespace(namespace string) (int64, error) {
+	err := db.evict(namespace, "")
+	if err != nil {
+		return 0, err
+	}
+	defer db.purgeLocal(namespace)
+	documents, err := db.redis.DeleteMatching(db.keys.documents(namespace))
+	if err != nil {
+		return documents, err
+	}
+	queries, err := db.redis.DeleteMatching(db.keys.queries(namespace))
+	return documents + queries, err
+}
+
+func (db *DB) CacheStats() database.CacheStats {
+	stats := db.metrics.stats()
+	stats.LocalEntries = db.local.len()
+	return stats
 }
 
-func (db DB) QueryHash(namespace string, filters, sort interface{}, offset, limit int) string {
+// InspectCache reads the cached document of id from both tiers, it never loads nor refreshes it
+func (db *DB) InspectCache(namespace, idStr string) (database.CacheEntry, error) {
+	key := db.keys.document(namespace, idStr)
+	inspected := database.CacheEntry{Key: key}
+	_, inspected.Local = db.local.get(key)
+
+	var entry cacheEntry
+	err := db.redis.Get(key, &entry)
+	if err == goredis.Nil {
+		return inspected, nil
+	}
+	if err != nil {
+		return inspected, err
+	}
+	inspected.Cached = len(entry.Data) > 0 || entry.Missing
+	inspected.Missing = entry.Missing
+	inspected.FreshUntil = entry.FreshUntil
+	inspected.Data = entry.Data
+	return inspected, nil
+}
+
+func (db *DB) EvictCache(namespace, idStr string) error {
+	return db.evict(namespace, idStr)
+}
+
+// versionedKey is the cache key of a query for the current namespace version,
+// the query is not cacheable when the version can't be read or inside a transaction
+func (db DB) versionedKey(namespace string, filters, sort interface{}, offset, limit int, fields ...string) (string, bool) {
+	if db.pending != nil {
+		return "", false
+	}
+	version, err := db.version(namespace)
+	if err != nil {
+		return "", false
+	}
+	return db.QueryKey(namespace, version, filters, sort, offset, limit, fields...), true
+}
+
+func (db DB) QueryKey(namespace string, version int64, filters, sort interface{}, offset, limit int, fields ...string) string {
 	q := Query{
-		Namespace: namespace,
-		Filters:   filters,
-		Sort:      sort,
-		Offset:    offset,
-		Limit:     limit,
+		Filters: filters,
+		Sort:    sort,
+		Offset:  offset,
+		Limit:   limit,
+		Fields:  fields,
 	}
 	jsonData, err := json.Marshal(q)
 	if err != nil {
 		db.logger.Println(err)
 	}
-	return base64.RawStdEncoding.EncodeToString(jsonData)
+	return db.keys.query(namespace, version, jsonData)
 }
 
 type Query struct {
-	Namespace string
-	Filters   interface{}
-	Sort      interface{}
-	Offset    int
-	Limit     int
+	Filters interface{}
+	Sort    interface{}
+	Offset  int
+	Limit   int
+	Fields  []string `json:",omitempty"`
+}
+
+// Pipeline wraps the stages of an aggregation so its cache key never matches the one of a filter
+type Pipeline struct {
+	Stages interface{}
 }
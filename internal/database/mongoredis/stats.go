@@ -0,0 +1,113 @@
+package mongoredis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/metrics"
+)
+
+// operations read through the cache
+const (
+	opFind      = "find"
+	opQuery     = "query"
+	opTotal     = "total"
+	opAggregate = "aggregate"
+)
+
+// results of a cached read
+const (
+	resultHit   = "hit"
+	resultStale = "stale"
+	resultMiss  = "miss"
+	resultError = "error"
+)
+
+var (
+	cacheOperations = []string{opFind, opQuery, opTotal, opAggregate}
+	cacheResults    = []string{resultHit, resultStale, resultMiss, resultError}
+)
+
+// cacheMetrics records the outcome of every cached read and eviction, a nil
+// cacheMetrics records nothing
+type cacheMetrics struct {
+	registry  *metrics.Registry
+	results   map[string]map[string]*metrics.Counter
+	durations map[string]*metrics.Histogram
+	mutex     sync.Mutex
+	evictions map[string]*metrics.Counter
+}
+
+func newCacheMetrics(registry *metrics.Registry) *cacheMetrics {
+	m := &cacheMetrics{
+		registry:  registry,
+		results:   make(map[string]map[string]*metrics.Counter),
+		durations: make(map[string]*metrics.Histogram),
+		evictions: make(map[string]*metrics.Counter),
+	}
+	for _, op := range cacheOperations {
+		m.results[op] = make(map[string]*metrics.Counter)
+		for _, result := range cacheResults {
+			m.results[op][result] = registry.Counter("cache_requests_total",
+				"Cached reads by operation and result, errors are redis failures served by mongo",
+				metrics.Labels{"operation": op, "result": result})
+		}
+		m.durations[op] = registry.Histogram("cache_request_duration_seconds",
+			"Duration of cached reads by operation, including the database on a miss",
+			metrics.Labels{"operation": op}, metrics.DefaultBuckets)
+	}
+	return m
+}
+
+func (m *cacheMetrics) observe(op, result string, start time.Time) {
+	if m == nil {
+		return
+	}
+	m.results[op][result].Inc()
+	m.durations[op].Observe(time.Since(start).Seconds())
+}
+
+func (m *cacheMetrics) evicted(namespace string) {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	counter, ok := m.evictions[namespace]
+	if !ok {
+		counter = m.registry.Counter("cache_evictions_total", "Cache evictions by namespace",
+			metrics.Labels{"namespace": namespace})
+		m.evictions[namespace] = counter
+	}
+	m.mutex.Unlock()
+	counter.Inc()
+}
+
+func (m *cacheMetrics) stats() database.CacheStats {
+	stats := database.CacheStats{
+		Operations: make(map[string]database.CacheOperationStats),
+		Evictions:  make(map[string]int64),
+	}
+	if m == nil {
+		return stats
+	}
+	for _, op := range cacheOperations {
+		results := m.results[op]
+		operation := database.CacheOperationStats{
+			Hits:      results[resultHit].Value(),
+			StaleHits: results[resultStale].Value(),
+			Misses:    results[resultMiss].Value(),
+			Errors:    results[resultError].Value(),
+		}
+		if count := m.durations[op].Count(); count > 0 {
+			operation.AverageLatency = time.Duration(m.durations[op].Sum() / float64(count) * float64(time.Second))
+		}
+		stats.Operations[op] = operation
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for namespace, counter := range m.evictions {
+		stats.Evictions[namespace] = counter.Value()
+	}
+	return stats
+}
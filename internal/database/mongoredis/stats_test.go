@@ -0,0 +1,61 @@
+package mongoredis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	goredis "github.com/go-redis/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStatsCountResults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, mongoMock, redisMock := newMockedDB(ctrl)
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).Return(goredis.Nil)
+	mongoMock.EXPECT().Find("users", "1", gomock.Any()).Return(nil)
+	redisMock.EXPECT().SetExpire("test:users:id:1", gomock.Any(), time.Minute).Return("OK", nil)
+	redisMock.EXPECT().Get("test:users:id:2", gomock.Any()).DoAndReturn(cachedValue(cachedUser{Name: "Walisson"}, time.Minute))
+	redisMock.EXPECT().Get("test:users:id:3", gomock.Any()).Return(fmt.Errorf("redis down"))
+	mongoMock.EXPECT().Find("users", "3", gomock.Any()).Return(nil)
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), nil).Times(2)
+	redisMock.EXPECT().Counter("test:users:version").Return(int64(0), fmt.Errorf("redis down"))
+	for _, id := range []string{"1", "2", "3"} {
+		var user cachedUser
+		assert.Nil(t, db.Find("users", id, &user))
+	}
+
+	redisMock.EXPECT().Delete("test:users:id:1").Return(nil)
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(1), nil)
+	assert.Nil(t, db.EvictCache("users", "1"))
+
+	stats := db.CacheStats()
+	find := stats.Operations[opFind]
+	assert.Equal(t, int64(1), find.Hits)
+	assert.Equal(t, int64(1), find.Misses)
+	assert.Equal(t, int64(1), find.Errors)
+	assert.Equal(t, int64(0), stats.Operations[opQuery].Hits)
+	assert.Equal(t, map[string]int64{"users": 1}, stats.Evictions)
+}
+
+func TestInspectCacheNeverLoads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	db, _, redisMock := newMockedDB(ctrl)
+
+	redisMock.EXPECT().Get("test:users:id:1", gomock.Any()).Return(goredis.Nil)
+	entry, err := db.InspectCache("users", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, "test:users:id:1", entry.Key)
+	assert.False(t, entry.Cached)
+
+	redisMock.EXPECT().Get("test:users:id:2", gomock.Any()).DoAndReturn(cachedValue(cachedUser{Name: "Walisson"}, time.Minute))
+	entry, err = db.InspectCache("users", "2")
+	assert.Nil(t, err)
+	assert.True(t, entry.Cached)
+	assert.JSONEq(t, `{"Name":"Walisson"}`, string(entry.Data))
+	assert.True(t, entry.FreshUntil.After(time.Now()))
+}
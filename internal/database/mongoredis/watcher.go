@@ -0,0 +1,74 @@
+package mongoredis
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/database"
+)
+
+// watchRetryDelay is how long a failed watcher waits before resuming the change stream
+const watchRetryDelay = 5 * time.Second
+
+// watcher evicts the cached entries of documents changed by any client,
+// including other instances and direct edits on the database
+type watcher struct {
+	source     database.Watcher
+	evict      func(namespace, idStr string) error
+	namespaces []string
+	logger     *log.Logger
+	cancel     context.CancelFunc
+	done       sync.WaitGroup
+}
+
+func newWatcher(source database.Watcher, evict func(namespace, idStr string) error, namespaces string, logger *log.Logger) *watcher {
+	w := &watcher{source: source, evict: evict, logger: logger}
+	for _, namespace := range strings.Split(namespaces, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace != "" {
+			w.namespaces = append(w.namespaces, namespace)
+		}
+	}
+	return w
+}
+
+func (w *watcher) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	for _, namespace := range w.namespaces {
+		w.done.Add(1)
+		go w.watch(ctx, namespace)
+	}
+}
+
+func (w *watcher) stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	w.done.Wait()
+}
+
+func (w *watcher) watch(ctx context.Context, namespace string) {
+	defer w.done.Done()
+	for {
+		err := w.source.Watch(ctx, namespace, w.handle)
+		if ctx.Err() != nil {
+			return
+		}
+		w.logger.Printf("change stream of %s stopped: %v", namespace, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
+
+// handle fails when the eviction does, so the change is handled again once the stream resumes
+func (w *watcher) handle(change database.Change) error {
+	return w.evict(change.Namespace, change.ID)
+}
@@ -0,0 +1,73 @@
+package mongoredis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWatcher replays the changes it was given, then waits for the watcher to stop
+type fakeWatcher struct {
+	changes []database.Change
+	handled chan database.Change
+}
+
+func (f *fakeWatcher) Watch(ctx context.Context, namespace string, fn func(change database.Change) error) error {
+	for len(f.changes) > 0 {
+		change := f.changes[0]
+		change.Namespace = namespace
+		err := fn(change)
+		if err != nil {
+			return err
+		}
+		f.changes = f.changes[1:]
+		f.handled <- change
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func TestWatcherEvictsChangedDocuments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, _, redisMock := newMockedDB(ctrl)
+	redisMock.EXPECT().Delete("test:users:id:1").Return(nil)
+	redisMock.EXPECT().Delete("test:users:id:2").Return(nil)
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(1), nil).Times(3)
+
+	source := &fakeWatcher{
+		changes: []database.Change{{Operation: "update", ID: "1"}, {Operation: "drop"}, {Operation: "delete", ID: "2"}},
+		handled: make(chan database.Change, 3),
+	}
+	w := newWatcher(source, db.evict, " users, ", configs.NewLog())
+	assert.Equal(t, []string{"users"}, w.namespaces)
+	w.start()
+	for i := 0; i < 3; i++ {
+		select {
+		case change := <-source.handled:
+			assert.Equal(t, "users", change.Namespace)
+		case <-time.After(2 * time.Second):
+			t.Fatal("change not handled")
+		}
+	}
+	w.stop()
+}
+
+func TestWatcherEvictFailureIsReturned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	db, _, redisMock := newMockedDB(ctrl)
+	redisMock.EXPECT().Delete("test:users:id:1").Return(fmt.Errorf("redis down"))
+	redisMock.EXPECT().Increment("test:users:version").Return(int64(0), fmt.Errorf("redis down"))
+
+	w := newWatcher(&fakeWatcher{}, db.evict, "users", configs.NewLog())
+	assert.NotNil(t, w.handle(database.Change{Operation: "update", Namespace: "users", ID: "1"}))
+}
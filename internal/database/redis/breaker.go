@@ -0,0 +1,312 @@
+package redis
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/metrics"
+	"github.com/go-redis/redis"
+)
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "open"
+	}
+}
+
+// ErrCircuitOpen is returned without calling redis while the circuit is open
+var ErrCircuitOpen = errors.New("redis circuit open")
+
+// maxPendingInvalidations bounds the deletes and increments kept for replay
+const maxPendingInvalidations = 10000
+
+// invalidations are the deletes, pattern deletes and increments that failed, replayed
+// once the circuit closes so entries cached before a write are not served after it
+type invalidations struct {
+	deletes    map[string]bool
+	patterns   map[string]bool
+	increments map[string]bool
+}
+
+func newInvalidations() invalidations {
+	return invalidations{deletes: map[string]bool{}, patterns: map[string]bool{}, increments: map[string]bool{}}
+}
+
+func (i invalidations) len() int {
+	return len(i.deletes) + len(i.patterns) + len(i.increments)
+}
+
+// Breaker stops calling redis after consecutive failures or slow calls, while open
+// every call fails at once so callers fall back to mongo. After the cooldown a single
+// call probes redis and closes the circuit again when it succeeds. Failed deletes and
+// increments, the cache invalidations, are replayed when it closes
+type Breaker struct {
+	db        database.RedisDB
+	threshold int
+	latency   time.Duration
+	cooldown  time.Duration
+	logger    *log.Logger
+	now       func() time.Time
+
+	mutex    sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+	pending  invalidations
+
+	rejected *metrics.Counter
+}
+
+func NewBreaker(db database.RedisDB, config *configs.EnvVarConfig, logger *log.Logger) *Breaker {
+	threshold, err := strconv.Atoi(config.RedisDBBreakerFailures)
+	if err != nil || threshold <= 0 {
+		logger.Printf("Invalid Redis breaker failures %s, using 5", config.RedisDBBreakerFailures)
+		threshold = 5
+	}
+	latency, err := time.ParseDuration(config.RedisDBBreakerLatency)
+	if err != nil || latency <= 0 {
+		logger.Printf("Invalid Redis breaker latency %s, using 500ms", config.RedisDBBreakerLatency)
+		latency = 500 * time.Millisecond
+	}
+	cooldown, err := time.ParseDuration(config.RedisDBBreakerCooldown)
+	if err != nil || cooldown <= 0 {
+		logger.Printf("Invalid Redis breaker cooldown %s, using 10s", config.RedisDBBreakerCooldown)
+		cooldown = 10 * time.Second
+	}
+	b := &Breaker{
+		db:        db,
+		threshold: threshold,
+		latency:   latency,
+		cooldown:  cooldown,
+		logger:    logger,
+		now:       time.Now,
+		pending:   newInvalidations(),
+		rejected:  metrics.Default.Counter("redis_circuit_rejected_total", "Redis calls skipped while the circuit was open", nil),
+	}
+	metrics.Default.GaugeFunc("redis_circuit_state", "Redis circuit state, 0 closed, 1 half-open, 2 open", nil, func() float64 {
+		return float64(b.State())
+	})
+	return b
+}
+
+func (b *Breaker) State() BreakerState {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.state
+}
+
+func (b *Breaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.transition(BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// done records the outcome of a call, a cache miss is a successful call. It
+// reports whether the call closed the circuit
+func (b *Breaker) done(err error, elapsed time.Duration) bool {
+	failed := (err != nil && err != redis.Nil) || elapsed > b.latency
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.probing = false
+		if failed {
+			b.open()
+			return false
+		}
+		b.failures = 0
+		b.transition(BreakerClosed)
+		return true
+	}
+	if !failed {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.state == BreakerClosed && b.failures >= b.threshold {
+		b.open()
+	}
+	return false
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.transition(BreakerOpen)
+}
+
+func (b *Breaker) transition(state BreakerState) {
+	if b.state == state {
+		return
+	}
+	b.logger.Printf("redis circuit %s", state)
+	b.state = state
+	metrics.Default.Counter("redis_circuit_transitions_total", "Redis circuit state changes", metrics.Labels{"to": state.String()}).Inc()
+}
+
+func (b *Breaker) call(fn func() error) error {
+	if !b.allow() {
+		b.rejected.Inc()
+		return ErrCircuitOpen
+	}
+	start := b.now()
+	err := fn()
+	if b.done(err, b.now().Sub(start)) {
+		b.replay()
+	}
+	return err
+}
+
+// invalidate runs a delete or an increment, keeping it for replay when it fails
+func (b *Breaker) invalidate(set func(invalidations) map[string]bool, key string, fn func() error) error {
+	err := b.call(fn)
+	if err == nil || err == redis.Nil {
+		return err
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.pending.len() >= maxPendingInvalidations {
+		b.logger.Printf("too many pending redis invalidations, dropping %s", key)
+		return err
+	}
+	set(b.pending)[key] = true
+	return err
+}
+
+// replay runs the invalidations that failed, those failing again are kept for the next replay
+func (b *Breaker) replay() {
+	b.mutex.Lock()
+	pending := b.pending
+	b.pending = newInvalidations()
+	b.mutex.Unlock()
+	if pending.len() == 0 {
+		return
+	}
+
+	b.logger.Printf("replaying %d redis invalidations", pending.len())
+	for pattern := range pending.patterns {
+		_, _ = b.DeleteMatching(pattern)
+	}
+	for key := range pending.increments {
+		_, _ = b.Increment(key)
+	}
+	for key := range pending.deletes {
+		_ = b.Delete(key)
+	}
+}
+
+func (b *Breaker) Disconnect() error {
+	return b.db.Disconnect()
+}
+
+func (b *Breaker) Ping() error {
+	return b.call(b.db.Ping)
+}
+
+func (b *Breaker) Set(idStr string, data interface{}) (string, error) {
+	var key string
+	err := b.call(func() (err error) {
+		key, err = b.db.Set(idStr, data)
+		return err
+	})
+	return key, err
+}
+
+func (b *Breaker) SetExpire(idStr string, data interface{}, ttl time.Duration) (string, error) {
+	var key string
+	err := b.call(func() (err error) {
+		key, err = b.db.SetExpire(idStr, data, ttl)
+		return err
+	})
+	return key, err
+}
+
+func (b *Breaker) Get(idStr string, dst interface{}) error {
+	return b.call(func() error {
+		return b.db.Get(idStr, dst)
+	})
+}
+
+func (b *Breaker) Delete(idStr string) error {
+	return b.invalidate(func(i invalidations) map[string]bool { return i.deletes }, idStr, func() error {
+		return b.db.Delete(idStr)
+	})
+}
+
+func (b *Breaker) Increment(key string) (int64, error) {
+	var value int64
+	err := b.invalidate(func(i invalidations) map[string]bool { return i.increments }, key, func() (err error) {
+		value, err = b.db.Increment(key)
+		return err
+	})
+	return value, err
+}
+
+func (b *Breaker) Counter(key string) (int64, error) {
+	var value int64
+	err := b.call(func() (err error) {
+		value, err = b.db.Counter(key)
+		return err
+	})
+	return value, err
+}
+
+func (b *Breaker) DeleteMatching(pattern string) (int64, error) {
+	var deleted int64
+	err := b.invalidate(func(i invalidations) map[string]bool { return i.patterns }, pattern, func() (err error) {
+		deleted, err = b.db.DeleteMatching(pattern)
+		return err
+	})
+	return deleted, err
+}
+
+func (b *Breaker) Publish(channel, message string) error {
+	return b.call(func() error {
+		return b.db.Publish(channel, message)
+	})
+}
+
+func (b *Breaker) Subscribe(channel string, fn func(message string)) (func() error, error) {
+	var unsubscribe func() error
+	err := b.call(func() (err error) {
+		unsubscribe, err = b.db.Subscribe(channel, fn)
+		return err
+	})
+	return unsubscribe, err
+}
@@ -0,0 +1,134 @@
+package redis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/go-redis/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBreaker(ctrl *gomock.Controller) (*Breaker, *database.MockRedisDB, *time.Time) {
+	redisMock := database.NewMockRedisDB(ctrl)
+	breaker := NewBreaker(redisMock, &configs.EnvVarConfig{
+		RedisDBBreakerFailures: "2",
+		RedisDBBreakerLatency:  "100ms",
+		RedisDBBreakerCooldown: "10s",
+	}, configs.NewLog())
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+	return breaker, redisMock, &now
+}
+
+func TestBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	breaker, redisMock, now := newTestBreaker(ctrl)
+
+	redisMock.EXPECT().Get("1", gomock.Any()).Return(fmt.Errorf("timeout")).Times(2)
+	redisMock.EXPECT().Get("2", gomock.Any()).Return(redis.Nil)
+	assert.NotNil(t, breaker.Get("1", nil))
+	assert.Equal(t, redis.Nil, breaker.Get("2", nil), "a miss is not a failure")
+	assert.NotNil(t, breaker.Get("1", nil))
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	redisMock.EXPECT().Delete("1").Return(fmt.Errorf("timeout"))
+	assert.NotNil(t, breaker.Delete("1"))
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	// no call reaches redis while open
+	assert.Equal(t, ErrCircuitOpen, breaker.Get("1", nil))
+	_, err := breaker.Increment("version:users")
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	*now = now.Add(11 * time.Second)
+	redisMock.EXPECT().Ping().Return(nil)
+	// the invalidations missed are replayed on close
+	redisMock.EXPECT().Delete("1").Return(nil)
+	redisMock.EXPECT().Increment("version:users").Return(int64(2), nil)
+	assert.Nil(t, breaker.Ping())
+	assert.Equal(t, BreakerClosed, breaker.State())
+}
+
+func TestBreakerReplaysInvalidationsOnClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	breaker, redisMock, now := newTestBreaker(ctrl)
+
+	cached := map[string]string{"doc:1": "before the write", "version:users": "1"}
+	redisMock.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(key string, dst interface{}) error {
+		value, ok := cached[key]
+		if !ok {
+			return redis.Nil
+		}
+		*dst.(*string) = value
+		return nil
+	}).AnyTimes()
+	redisMock.EXPECT().Delete(gomock.Any()).DoAndReturn(func(key string) error {
+		delete(cached, key)
+		return nil
+	}).AnyTimes()
+	redisMock.EXPECT().Increment(gomock.Any()).DoAndReturn(func(key string) (int64, error) {
+		cached[key] += "+1"
+		return 2, nil
+	}).AnyTimes()
+	redisMock.EXPECT().Ping().Return(fmt.Errorf("timeout")).Times(2)
+
+	assert.NotNil(t, breaker.Ping())
+	assert.NotNil(t, breaker.Ping())
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	// a write while open evicts its document and bumps the version, both are rejected
+	assert.Equal(t, ErrCircuitOpen, breaker.Delete("doc:1"))
+	_, err := breaker.Increment("version:users")
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	*now = now.Add(11 * time.Second)
+	var version string
+	assert.Nil(t, breaker.Get("version:users", &version), "the probe closes the circuit")
+	assert.Equal(t, BreakerClosed, breaker.State())
+
+	var value string
+	assert.Equal(t, redis.Nil, breaker.Get("doc:1", &value), "the entry cached before the write must not be served")
+	assert.Nil(t, breaker.Get("version:users", &version))
+	assert.Equal(t, "1+1", version)
+}
+
+func TestBreakerCountsSlowCallsAsFailures(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	breaker, redisMock, now := newTestBreaker(ctrl)
+
+	redisMock.EXPECT().Get("1", gomock.Any()).DoAndReturn(func(key string, dst interface{}) error {
+		*now = now.Add(time.Second)
+		return nil
+	}).Times(2)
+	assert.Nil(t, breaker.Get("1", nil))
+	assert.Nil(t, breaker.Get("1", nil))
+	assert.Equal(t, BreakerOpen, breaker.State())
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	breaker, redisMock, now := newTestBreaker(ctrl)
+
+	redisMock.EXPECT().Ping().Return(fmt.Errorf("down")).Times(2)
+	breaker.Ping()
+	breaker.Ping()
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	*now = now.Add(11 * time.Second)
+	redisMock.EXPECT().Get("1", gomock.Any()).DoAndReturn(func(key string, dst interface{}) error {
+		assert.Equal(t, BreakerHalfOpen, breaker.State())
+		assert.Equal(t, ErrCircuitOpen, breaker.Delete("2"), "only one probe at a time")
+		return fmt.Errorf("still down")
+	})
+	assert.NotNil(t, breaker.Get("1", nil))
+	assert.Equal(t, BreakerOpen, breaker.State(), "a failed probe opens the circuit again")
+	assert.Equal(t, ErrCircuitOpen, breaker.Get("1", nil))
+}
@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis"
+)
+
+const (
+	ModeSingle   = "single"
+	ModeSentinel = "sentinel"
+	ModeCluster  = "cluster"
+)
+
+// newClient builds the client of the configured deployment, single node, sentinel or cluster
+func (db *DB) newClient() (redis.UniversalClient, error) {
+	tlsConfig, err := db.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	switch db.config.RedisDBMode {
+	case ModeSentinel:
+		addrs := splitAddrs(db.config.RedisDBSentinelAddrs)
+		if db.config.RedisDBSentinelMaster == "" || len(addrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel requires the master name and sentinel addresses")
+		}
+		password, database, onConnect := db.auth(db.database())
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    db.config.RedisDBSentinelMaster,
+			SentinelAddrs: addrs,
+			Password:      password,
+			OnConnect:     onConnect,
+			MaxRetries:    5,
+			DialTimeout:   db.timeout,
+			DB:            database,
+			TLSConfig:     tlsConfig,
+		}), nil
+	case ModeCluster:
+		addrs := splitAddrs(db.config.RedisDBClusterAddrs)
+		if len(addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster requires the seed node addresses")
+		}
+		// cluster nodes only have the database 0
+		password, _, onConnect := db.auth(0)
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:       addrs,
+			Password:    password,
+			OnConnect:   onConnect,
+			MaxRetries:  5,
+			DialTimeout: db.timeout,
+			TLSConfig:   tlsConfig,
+		}), nil
+	case ModeSingle, "":
+		password, database, onConnect := db.auth(db.database())
+		return redis.NewClient(&redis.Options{
+			Addr:        fmt.Sprintf("%s:%s", db.config.RedisDBHost, db.config.RedisDBPort),
+			Password:    password,
+			OnConnect:   onConnect,
+			MaxRetries:  5,
+			DialTimeout: db.timeout,
+			DB:          database,
+			TLSConfig:   tlsConfig,
+		}), nil
+	default:
+		return nil, fmt.Errorf("invalid redis mode %s", db.config.RedisDBMode)
+	}
+}
+
+func (db *DB) database() int {
+	dbs, err := strconv.Atoi(db.config.RedisDBDatabase)
+	if err != nil {
+		db.logger.Printf("Invalid Redis Database %s, using 1", db.config.RedisDBDatabase)
+		dbs = 1
+	}
+	return dbs
+}
+
+// auth returns the password and database given to the client. With an username the client
+// can't authenticate by itself, it would select the database before authenticating, so an
+// ACL AUTH followed by the SELECT of database runs on every new connection instead
+func (db *DB) auth(database int) (string, int, func(conn *redis.Conn) error) {
+	if db.config.RedisDBUsername == "" {
+		return db.config.RedisDBPassword, database, nil
+	}
+	username, password := db.config.RedisDBUsername, db.config.RedisDBPassword
+	return "", 0, func(conn *redis.Conn) error {
+		cmd := redis.NewStatusCmd("AUTH", username, password)
+		err := conn.Process(cmd)
+		if err == nil {
+			err = cmd.Err()
+		}
+		if err != nil || database == 0 {
+			return err
+		}
+		return conn.Select(database).Err()
+	}
+}
+
+func (db *DB) tlsConfig() (*tls.Config, error) {
+	if db.config.RedisDBTLS != "true" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// #nosec G402 opt-in for self signed certificates in development
+		InsecureSkipVerify: db.config.RedisDBTLSInsecureSkipVerify == "true",
+	}
+	if db.config.RedisDBTLSCACert != "" {
+		pem, err := ioutil.ReadFile(db.config.RedisDBTLSCACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid redis CA certificate %s", db.config.RedisDBTLSCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+func splitAddrs(value string) []string {
+	addrs := []string{}
+	for _, addr := range strings.Split(value, ",") {
+		addr = strings.TrimSpace(addr)
+		if addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
@@ -0,0 +1,148 @@
+package redis
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/go-redis/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientModes(t *testing.T) {
+	tests := []struct {
+		name   string
+		config configs.EnvVarConfig
+		client interface{}
+		fail   bool
+	}{
+		{name: "single", config: configs.EnvVarConfig{RedisDBHost: "localhost", RedisDBPort: "6379"}, client: &redis.Client{}},
+		{name: "sentinel", config: configs.EnvVarConfig{RedisDBMode: ModeSentinel, RedisDBSentinelMaster: "mymaster", RedisDBSentinelAddrs: "a:26379, b:26379"}, client: &redis.Client{}},
+		{name: "sentinel without master", config: configs.EnvVarConfig{RedisDBMode: ModeSentinel, RedisDBSentinelAddrs: "a:26379"}, fail: true},
+		{name: "cluster", config: configs.EnvVarConfig{RedisDBMode: ModeCluster, RedisDBClusterAddrs: "a:7000,b:7001"}, client: &redis.ClusterClient{}},
+		{name: "cluster without seeds", config: configs.EnvVarConfig{RedisDBMode: ModeCluster, RedisDBClusterAddrs: " , "}, fail: true},
+		{name: "unknown mode", config: configs.EnvVarConfig{RedisDBMode: "ring"}, fail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.RedisDBDatabase = "1"
+			db, err := NewDB(&tt.config, configs.NewLog())
+			if tt.fail {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.IsType(t, tt.client, db.(*DB)._client)
+			db.Disconnect()
+		})
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	db := &DB{config: &configs.EnvVarConfig{RedisDBPassword: "secret"}}
+	password, database, onConnect := db.auth(1)
+	assert.Equal(t, "secret", password)
+	assert.Equal(t, 1, database)
+	assert.Nil(t, onConnect)
+
+	db.config.RedisDBUsername = "cache"
+	password, database, onConnect = db.auth(1)
+	assert.Equal(t, "", password, "ACL users authenticate on connect")
+	assert.Equal(t, 0, database, "the database is selected after authenticating")
+	assert.NotNil(t, onConnect)
+}
+
+// fakeRedis answers every command with OK, or NOAUTH until AUTH is received, recording them
+func fakeRedis(t *testing.T) (string, func() []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	var mutex sync.Mutex
+	commands := []string{}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		authenticated := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			args := make([]string, 0, count)
+			for i := 0; i < count; i++ {
+				_, _ = reader.ReadString('\n')
+				arg, _ := reader.ReadString('\n')
+				args = append(args, strings.TrimSpace(arg))
+			}
+			mutex.Lock()
+			commands = append(commands, strings.Join(args, " "))
+			mutex.Unlock()
+			switch {
+			case strings.EqualFold(args[0], "AUTH"):
+				authenticated = true
+				_, _ = conn.Write([]byte("+OK\r\n"))
+			case !authenticated:
+				_, _ = conn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+			case strings.EqualFold(args[0], "PING"):
+				_, _ = conn.Write([]byte("+PONG\r\n"))
+			default:
+				_, _ = conn.Write([]byte("+OK\r\n"))
+			}
+		}
+	}()
+	return listener.Addr().String(), func() []string {
+		listener.Close()
+		mutex.Lock()
+		defer mutex.Unlock()
+		return commands
+	}
+}
+
+func TestClientACLAuthSelectsDatabase(t *testing.T) {
+	addr, commands := fakeRedis(t)
+	host, port, _ := net.SplitHostPort(addr)
+	db := &DB{config: &configs.EnvVarConfig{
+		RedisDBHost: host, RedisDBPort: port, RedisDBDatabase: "3",
+		RedisDBUsername: "cache", RedisDBPassword: "secret",
+	}, timeout: time.Second, logger: configs.NewLog()}
+	client, err := db.newClient()
+	assert.Nil(t, err)
+	defer client.Close()
+
+	assert.Nil(t, client.Ping().Err())
+	assert.Equal(t, []string{"AUTH cache secret", "select 3", "ping"}, commands())
+}
+
+func TestClientTLS(t *testing.T) {
+	db := &DB{config: &configs.EnvVarConfig{}}
+	tlsConfig, err := db.tlsConfig()
+	assert.Nil(t, err)
+	assert.Nil(t, tlsConfig)
+
+	db.config.RedisDBTLS = "true"
+	tlsConfig, err = db.tlsConfig()
+	assert.Nil(t, err)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	dir, err := ioutil.TempDir("", "redis-tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	invalidCA := filepath.Join(dir, "ca.pem")
+	assert.Nil(t, ioutil.WriteFile(invalidCA, []byte("not a certificate"), 0600))
+
+	db.config.RedisDBTLSCACert = invalidCA
+	_, err = db.tlsConfig()
+	assert.NotNil(t, err)
+}
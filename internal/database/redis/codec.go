@@ -0,0 +1,89 @@
+package redis
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/vmihailenco/msgpack/v4"
+)
+
+const (
+	CodecJSON    = "json"
+	CodecMsgpack = "msgpack"
+	// CodecReJSON stores values with the JSON.* commands of the RedisJSON module
+	CodecReJSON = "rejson"
+)
+
+// codec decides how values are stored, every codec writes the value and its expiry atomically
+type codec interface {
+	set(key string, data interface{}, ttl time.Duration) error
+	get(key string, dst interface{}) error
+}
+
+func newCodec(name string, client redis.UniversalClient) (codec, bool) {
+	switch name {
+	case CodecJSON:
+		return stringCodec{client: client, marshal: json.Marshal, unmarshal: json.Unmarshal}, true
+	case CodecMsgpack:
+		return stringCodec{client: client, marshal: msgpack.Marshal, unmarshal: msgpack.Unmarshal}, true
+	case CodecReJSON:
+		return rejsonCodec{client: client}, true
+	default:
+		return nil, false
+	}
+}
+
+// stringCodec stores encoded values as plain strings, it works on any redis
+type stringCodec struct {
+	client    redis.UniversalClient
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+}
+
+func (c stringCodec) set(key string, data interface{}, ttl time.Duration) error {
+	value, err := c.marshal(data)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(key, value, ttl).Err()
+}
+
+func (c stringCodec) get(key string, dst interface{}) error {
+	value, err := c.client.Get(key).Bytes()
+	if err != nil {
+		return err
+	}
+	return c.unmarshal(value, dst)
+}
+
+// rejsonCodec requires the RedisJSON module, JSON.SET has no expiry so it runs
+// in a transaction with EXPIRE
+type rejsonCodec struct {
+	client redis.UniversalClient
+}
+
+func (c rejsonCodec) set(key string, data interface{}, ttl time.Duration) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	pipe := c.client.TxPipeline()
+	pipe.Process(redis.NewStatusCmd("JSON.SET", key, ".", string(js)))
+	pipe.Expire(key, ttl)
+	_, err = pipe.Exec()
+	return err
+}
+
+func (c rejsonCodec) get(key string, dst interface{}) error {
+	cmd := redis.NewStringCmd("JSON.GET", key)
+	err := c.client.Process(cmd)
+	if err == nil {
+		err = cmd.Err()
+	}
+	jsonString := cmd.Val()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(jsonString), dst)
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecs(t *testing.T) {
+	if !checkRedis() {
+		t.Skip()
+		return
+	}
+	for _, codec := range []string{CodecJSON, CodecMsgpack} {
+		t.Run(codec, func(t *testing.T) {
+			config := getConfig(t)
+			config.RedisDBCodec = codec
+			db, err := NewDB(config, configs.NewLog())
+			assert.Nil(t, err)
+			defer db.Disconnect()
+
+			key := uuid.NewString()
+			_, err = db.SetExpire(key, TestEntity{ID: key, Name: "Walisson"}, time.Minute)
+			assert.Nil(t, err)
+
+			var entity TestEntity
+			assert.Nil(t, db.Get(key, &entity))
+			assert.Equal(t, "Walisson", entity.Name)
+
+			ttl, err := db.(*DB)._client.TTL(key).Result()
+			assert.Nil(t, err)
+			assert.True(t, ttl > 0 && ttl <= time.Minute, "value and expiry are written together")
+			assert.Nil(t, db.Delete(key))
+		})
+	}
+}
+
+func TestInvalidCodecFallsBackToJSON(t *testing.T) {
+	_, ok := newCodec("xml", nil)
+	assert.False(t, ok)
+
+	db, _ := NewDB(&configs.EnvVarConfig{RedisDBCodec: "xml", RedisDBDatabase: "1"}, configs.NewLog())
+	assert.IsType(t, stringCodec{}, db.(*DB).codec)
+}
@@ -1,13 +1,10 @@
 package redis
 
 import (
-	"encoding/json"
-	"fmt"
 	"log"
-	"strconv"
+	"sync/atomic"
 	"time"
 
-	"github.com/KromDaniel/rejonson"
 	"github.com/Shodocan/UserService/internal/configs"
 	"github.com/Shodocan/UserService/internal/database"
 	"github.com/go-redis/redis"
@@ -18,7 +15,8 @@ type DB struct {
 	timeout time.Duration
 	config  *configs.EnvVarConfig
 	logger  *log.Logger
-	_client *rejonson.Client
+	codec   codec
+	_client redis.UniversalClient
 }
 
 func NewDB(config *configs.EnvVarConfig, logger *log.Logger) (database.RedisDB, error) {
@@ -40,26 +38,25 @@ func NewDB(config *configs.EnvVarConfig, logger *log.Logger) (database.RedisDB,
 	}
 
 	err = db.init()
-	return db, err
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
 }
 
 func (db *DB) init() error {
-	var err error
-	dbs, err := strconv.Atoi(db.config.RedisDBDatabase)
+	client, err := db.newClient()
 	if err != nil {
-		db.logger.Printf("Invalid Redis Database %s, using 1", db.config.RedisDBDatabase)
-		dbs = 1
+		return err
 	}
-	goRedisClient := redis.NewClient(&redis.Options{
-		Addr:        fmt.Sprintf("%s:%s", db.config.RedisDBHost, db.config.RedisDBPort),
-		Password:    db.config.RedisDBPassword,
-		MaxRetries:  5,
-		DialTimeout: db.timeout,
-		DB:          dbs,
-	})
-	client := rejonson.ExtendClient(goRedisClient)
 	db._client = client
-	return err
+	codec, ok := newCodec(db.config.RedisDBCodec, client)
+	if !ok {
+		db.logger.Printf("Invalid Redis codec %s, using %s", db.config.RedisDBCodec, CodecJSON)
+		codec, _ = newCodec(CodecJSON, client)
+	}
+	db.codec = codec
+	return nil
 }
 
 func (db *DB) Disconnect() error {
@@ -71,21 +68,95 @@ func (db *DB) Ping() error {
 }
 
 func (db DB) Set(idStr string, data interface{}) (string, error) {
-	js, _ := json.Marshal(data)
-	_, err := db._client.JsonSet(idStr, ".", string(js)).Result()
-	db._client.Expire(idStr, db.cache)
-	return idStr, err
+	return db.SetExpire(idStr, data, db.cache)
+}
+
+func (db DB) SetExpire(idStr string, data interface{}, ttl time.Duration) (string, error) {
+	return idStr, db.codec.set(idStr, data, ttl)
 }
 
 func (db DB) Get(idStr string, dst interface{}) error {
-	jsonString, err := db._client.JsonGet(idStr).Result()
-	if err != nil {
-		return err
-	}
-	return json.Unmarshal([]byte(jsonString), dst)
+	return db.codec.get(idStr, dst)
 }
 
 func (db DB) Delete(idStr string) error {
 	sts := db._client.Del(idStr)
 	return sts.Err()
 }
+
+func (db DB) Increment(key string) (int64, error) {
+	return db._client.Incr(key).Result()
+}
+
+func (db DB) Counter(key string) (int64, error) {
+	value, err := db._client.Get(key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, err
+}
+
+func (db DB) Publish(channel, message string) error {
+	return db._client.Publish(channel, message).Err()
+}
+
+func (db DB) Subscribe(channel string, fn func(message string)) (func() error, error) {
+	pubsub := db._client.Subscribe(channel)
+	// waits for the confirmation so no message published after Subscribe is lost
+	_, err := pubsub.Receive()
+	if err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	go func() {
+		for message := range pubsub.Channel() {
+			fn(message.Payload)
+		}
+	}()
+	return pubsub.Close, nil
+}
+
+func (db DB) DeleteMatching(pattern string) (int64, error) {
+	cluster, ok := db._client.(*redis.ClusterClient)
+	if !ok {
+		return deleteMatching(db._client, pattern)
+	}
+	// keys are spread over the masters, each one is scanned
+	var deleted int64
+	err := cluster.ForEachMaster(func(node *redis.Client) error {
+		count, err := deleteMatching(node, pattern)
+		atomic.AddInt64(&deleted, count)
+		return err
+	})
+	return deleted, err
+}
+
+// deleteMatching scans instead of KEYS so redis is never blocked, keys are deleted one
+// by one in a pipeline as a cluster refuses multi key commands across slots
+func deleteMatching(client redis.Cmdable, pattern string) (int64, error) {
+	var cursor uint64
+	var deleted int64
+	for {
+		keys, next, err := client.Scan(cursor, pattern, 100).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			pipe := client.Pipeline()
+			for _, key := range keys {
+				pipe.Del(key)
+			}
+			cmds, err := pipe.Exec()
+			if err != nil {
+				return deleted, err
+			}
+			for _, cmd := range cmds {
+				deleted += cmd.(*redis.IntCmd).Val()
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
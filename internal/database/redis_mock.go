@@ -6,6 +6,7 @@ package database
 
 import (
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -33,6 +34,21 @@ func (m *MockRedisDB) EXPECT() *MockRedisDBMockRecorder {
 	return m.recorder
 }
 
+// Counter mocks base method.
+func (m *MockRedisDB) Counter(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Counter", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Counter indicates an expected call of Counter.
+func (mr *MockRedisDBMockRecorder) Counter(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Counter", reflect.TypeOf((*MockRedisDB)(nil).Counter), arg0)
+}
+
 // Delete mocks base method.
 func (m *MockRedisDB) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -47,6 +63,21 @@ func (mr *MockRedisDBMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRedisDB)(nil).Delete), arg0)
 }
 
+// DeleteMatching mocks base method.
+func (m *MockRedisDB) DeleteMatching(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMatching", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMatching indicates an expected call of DeleteMatching.
+func (mr *MockRedisDBMockRecorder) DeleteMatching(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMatching", reflect.TypeOf((*MockRedisDB)(nil).DeleteMatching), arg0)
+}
+
 // Disconnect mocks base method.
 func (m *MockRedisDB) Disconnect() error {
 	m.ctrl.T.Helper()
@@ -75,6 +106,21 @@ func (mr *MockRedisDBMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockRedisDB)(nil).Get), arg0, arg1)
 }
 
+// Increment mocks base method.
+func (m *MockRedisDB) Increment(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Increment", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Increment indicates an expected call of Increment.
+func (mr *MockRedisDBMockRecorder) Increment(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockRedisDB)(nil).Increment), arg0)
+}
+
 // Ping mocks base method.
 func (m *MockRedisDB) Ping() error {
 	m.ctrl.T.Helper()
@@ -89,6 +135,20 @@ func (mr *MockRedisDBMockRecorder) Ping() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRedisDB)(nil).Ping))
 }
 
+// Publish mocks base method.
+func (m *MockRedisDB) Publish(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockRedisDBMockRecorder) Publish(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockRedisDB)(nil).Publish), arg0, arg1)
+}
+
 // Set mocks base method.
 func (m *MockRedisDB) Set(arg0 string, arg1 interface{}) (string, error) {
 	m.ctrl.T.Helper()
@@ -103,3 +163,33 @@ func (mr *MockRedisDBMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockRedisDB)(nil).Set), arg0, arg1)
 }
+
+// SetExpire mocks base method.
+func (m *MockRedisDB) SetExpire(arg0 string, arg1 interface{}, arg2 time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetExpire", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetExpire indicates an expected call of SetExpire.
+func (mr *MockRedisDBMockRecorder) SetExpire(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExpire", reflect.TypeOf((*MockRedisDB)(nil).SetExpire), arg0, arg1, arg2)
+}
+
+// Subscribe mocks base method.
+func (m *MockRedisDB) Subscribe(arg0 string, arg1 func(string)) (func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", arg0, arg1)
+	ret0, _ := ret[0].(func() error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockRedisDBMockRecorder) Subscribe(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockRedisDB)(nil).Subscribe), arg0, arg1)
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// UserGrouping is what the users of an aggregation are counted by
+type UserGrouping string
+
+const (
+	// GroupByAge counts the users by age bracket, the brackets start at each bucket
+	GroupByAge UserGrouping = "age"
+	// GroupByEmailDomain counts the users by the lower cased domain of their email
+	GroupByEmailDomain UserGrouping = "emailDomain"
+	// GroupByCity counts the users by city, the last comma separated part of their address
+	GroupByCity UserGrouping = "city"
+	// GroupByCreatedDay counts the users by the UTC day they were created, like 2021-05-30
+	GroupByCreatedDay UserGrouping = "createdDay"
+	// GroupByCreatedMonth counts the users by the UTC month they were created, like 2021-05
+	GroupByCreatedMonth UserGrouping = "createdMonth"
+)
+
+const (
+	// MaxAgeBuckets bounds the age brackets of an aggregation
+	MaxAgeBuckets = 20
+	// MaxBucketAge bounds the lower bounds of the age brackets
+	MaxBucketAge = 200
+	// MaxUserGroups bounds the groups of an aggregation, the largest ones are kept
+	MaxUserGroups = 100
+	// UnknownGroup counts the users without a value to group by, like ages under the first bucket
+	UnknownGroup = "unknown"
+)
+
+// DefaultAgeBuckets are the age brackets used when an aggregation by age sets none
+var DefaultAgeBuckets = []int{0, 18, 25, 35, 45, 55, 65}
+
+var userGroupings = map[UserGrouping]bool{
+	GroupByAge:          true,
+	GroupByEmailDomain:  true,
+	GroupByCity:         true,
+	GroupByCreatedDay:   true,
+	GroupByCreatedMonth: true,
+}
+
+// UserAggregation counts the users matching Query by GroupBy. Buckets are the
+// increasing lower bounds of the age brackets, the last bracket has no upper bound
+type UserAggregation struct {
+	Query   UserQuery
+	GroupBy UserGrouping
+	Buckets []int
+}
+
+// UserGroup is the number of users sharing a key
+type UserGroup struct {
+	Key   string `json:"key" example:"18-24"`
+	Count int    `json:"count" example:"42"`
+}
+
+// ParseGrouping validates what the users are counted by
+func ParseGrouping(groupBy string) (UserGrouping, error) {
+	grouping := UserGrouping(groupBy)
+	if !userGroupings[grouping] {
+		return grouping, fmt.Errorf("can't group by %q, only by age, emailDomain, city, createdDay and createdMonth", groupBy)
+	}
+	return grouping, nil
+}
+
+// CheckBuckets validates the lower bounds of the age brackets
+func CheckBuckets(buckets []int) error {
+	if len(buckets) > MaxAgeBuckets {
+		return fmt.Errorf("%d buckets, at most %d are allowed", len(buckets), MaxAgeBuckets)
+	}
+	for i, bucket := range buckets {
+		if bucket < 0 {
+			return fmt.Errorf("bucket %d is negative", bucket)
+		}
+		if bucket > MaxBucketAge {
+			return fmt.Errorf("bucket %d is over %d", bucket, MaxBucketAge)
+		}
+		if i > 0 && bucket <= buckets[i-1] {
+			return fmt.Errorf("buckets must be increasing, %d follows %d", bucket, buckets[i-1])
+		}
+	}
+	return nil
+}
+
+// AgeBracket names the bracket starting at the bucket i, like 18-24, or 65+ for the last one
+func AgeBracket(buckets []int, i int) string {
+	if i == len(buckets)-1 {
+		return strconv.Itoa(buckets[i]) + "+"
+	}
+	return fmt.Sprintf("%d-%d", buckets[i], buckets[i+1]-1)
+}
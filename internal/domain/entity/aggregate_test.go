@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGrouping(t *testing.T) {
+	grouping, err := ParseGrouping("emailDomain")
+	assert.Nil(t, err)
+	assert.Equal(t, GroupByEmailDomain, grouping)
+
+	_, err = ParseGrouping("password")
+	assert.NotNil(t, err)
+	_, err = ParseGrouping("")
+	assert.NotNil(t, err)
+}
+
+func TestCheckBuckets(t *testing.T) {
+	assert.Nil(t, CheckBuckets(DefaultAgeBuckets))
+	assert.Nil(t, CheckBuckets(nil))
+	assert.NotNil(t, CheckBuckets([]int{0, 18, 18}))
+	assert.NotNil(t, CheckBuckets([]int{30, 18}))
+	assert.NotNil(t, CheckBuckets([]int{-1, 18}))
+	assert.NotNil(t, CheckBuckets(make([]int, MaxAgeBuckets+1)))
+	assert.Nil(t, CheckBuckets([]int{0, MaxBucketAge}))
+	assert.NotNil(t, CheckBuckets([]int{0, MaxBucketAge + 1}))
+	assert.NotNil(t, CheckBuckets([]int{0, math.MaxInt32}), "mongo rejects a boundary past the last one")
+}
+
+func TestAgeBracket(t *testing.T) {
+	buckets := []int{0, 18, 65}
+	assert.Equal(t, "0-17", AgeBracket(buckets, 0))
+	assert.Equal(t, "18-64", AgeBracket(buckets, 1))
+	assert.Equal(t, "65+", AgeBracket(buckets, 2))
+}
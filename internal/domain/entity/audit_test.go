@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildChain(size int) []AuditEvent {
+	chain := []AuditEvent{}
+	prevHash := ""
+	for i := 1; i <= size; i++ {
+		event := NewAuditEvent(AuditContext{Actor: "admin", Client: "tests", IP: "127.0.0.1"}, AuditUpdate, "123", []string{"name"}, true)
+		event.Sequence = int64(i)
+		event.PrevHash = prevHash
+		event.Hash = event.ComputeHash()
+		prevHash = event.Hash
+		chain = append(chain, event)
+	}
+	return chain
+}
+
+func TestAuditChainVerification(t *testing.T) {
+	chain := buildChain(4)
+	assert.Nil(t, VerifyAuditChain(chain), "untouched chain must be valid")
+
+	tampered := buildChain(4)
+	tampered[2].Actor = "someone else"
+	assert.NotNil(t, VerifyAuditChain(tampered), "changing an event must break its hash")
+
+	rehashed := buildChain(4)
+	rehashed[2].Fields = []string{"email"}
+	rehashed[2].Hash = rehashed[2].ComputeHash()
+	assert.NotNil(t, VerifyAuditChain(rehashed), "rehashing an event must break the link to the next one")
+
+	removed := buildChain(4)
+	removed = append(removed[:1], removed[2:]...)
+	assert.NotNil(t, VerifyAuditChain(removed), "removing an event must break the chain")
+}
+
+func TestChangedFields(t *testing.T) {
+	before := User{Name: "Walisson", Age: 28, Email: "wdcasonatto@gmail.com", Password: "1", Address: "Rua das ..."}
+	after := User{Name: "Walisson", Age: 29, Email: "wdcasonatto@gmail.com", Password: "2", Address: "Rua das ..."}
+
+	assert.Equal(t, []string{"age", "password"}, ChangedFields(before, after))
+	assert.Equal(t, []string{}, ChangedFields(before, before))
+	assert.Equal(t, []string{"name", "age", "email", "password", "address"}, ChangedFields(User{}, before))
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CacheStats counts the cached reads of the instance serving the request since it started
+type CacheStats struct {
+	Operations map[string]CacheOperationStats `json:"operations"`
+	// Evictions by namespace
+	Evictions    map[string]int64 `json:"evictions"`
+	LocalEntries int              `json:"localEntries"`
+}
+
+type CacheOperationStats struct {
+	Hits      int64 `json:"hits"`
+	StaleHits int64 `json:"staleHits"`
+	Misses    int64 `json:"misses"`
+	// Errors are reads redis failed, they are served by the database
+	Errors int64 `json:"errors"`
+	// HitRatio counts stale hits as hits
+	HitRatio         float64 `json:"hitRatio"`
+	AverageLatencyMs float64 `json:"averageLatencyMs"`
+}
+
+// CacheEntry is the cached state of a user
+type CacheEntry struct {
+	Key    string `json:"key"`
+	Cached bool   `json:"cached"`
+	Local  bool   `json:"local"`
+	// Missing records a cached not found
+	Missing    bool            `json:"missing"`
+	FreshUntil *time.Time      `json:"freshUntil,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty" swaggertype:"object"`
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Cursor is a position in a sorted listing, the sort values and the id of the user next to it.
+// Backward cursors list the users before the position instead of after it
+type Cursor struct {
+	Sort     string        `json:"s"`
+	Values   []interface{} `json:"v"`
+	ID       string        `json:"id"`
+	Backward bool          `json:"b,omitempty"`
+}
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// SortKey describes the sort rules like the sort parameter, -name,age
+func SortKey(sort []SortRule) string {
+	rules := make([]string, 0, len(sort))
+	for _, rule := range sort {
+		if rule.Descending {
+			rules = append(rules, "-"+string(rule.Field))
+		} else {
+			rules = append(rules, string(rule.Field))
+		}
+	}
+	return strings.Join(rules, ",")
+}
+
+// SortValue is the value of a sortable field
+func (u User) SortValue(field UserFied) interface{} {
+	switch field {
+	case Name:
+		return u.Name
+	case Age:
+		return u.Age
+	case Email:
+		return u.Email
+	case Address:
+		return u.Address
+	default:
+		return nil
+	}
+}
+
+// NewCursor points after the user, or before it when backward
+func NewCursor(user User, sort []SortRule, backward bool) Cursor {
+	values := make([]interface{}, 0, len(sort))
+	for _, rule := range sort {
+		values = append(values, user.SortValue(rule.Field))
+	}
+	return Cursor{Sort: SortKey(sort), Values: values, ID: user.ID, Backward: backward}
+}
+
+// Encode returns the opaque token handed to the clients
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads a token, it must have been made for the same sort
+func DecodeCursor(token string, sort []SortRule) (Cursor, error) {
+	cursor := Cursor{}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return cursor, errInvalidCursor
+	}
+	if SortsByScore(sort) {
+		return cursor, errors.New("a sort by score can't be paginated by cursor")
+	}
+	if cursor.Sort != SortKey(sort) {
+		return cursor, errors.New("the cursor was made for another sort")
+	}
+	if id, err := hex.DecodeString(cursor.ID); err != nil || len(id) != 12 || len(cursor.Values) != len(sort) {
+		return cursor, errInvalidCursor
+	}
+	for i, rule := range sort {
+		fieldType, _ := rule.Field.Type()
+		value, err := convertValue(fieldType, cursor.Values[i])
+		if err != nil {
+			return cursor, errInvalidCursor
+		}
+		cursor.Values[i] = value
+	}
+	return cursor, nil
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	sort := []SortRule{{Field: Name, Descending: true}, {Field: Age}}
+	user := User{ID: "5f1d7f9c7d1e2a0001a1b2c3", Name: "Luke Skywalker", Age: 24}
+	cursor := NewCursor(user, sort, true)
+	assert.Equal(t, "-name,age", cursor.Sort)
+
+	decoded, err := DecodeCursor(cursor.Encode(), sort)
+	assert.Nil(t, err)
+	assert.Equal(t, cursor, decoded, "numbers must keep their type")
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	sort := []SortRule{{Field: Age}}
+	tokens := map[string]string{
+		"not base64":   "%%%",
+		"not json":     Cursor{}.Encode()[:3],
+		"another sort": NewCursor(User{ID: "5f1d7f9c7d1e2a0001a1b2c3"}, []SortRule{{Field: Name}}, false).Encode(),
+		"bad id":       Cursor{Sort: "age", Values: []interface{}{1}, ID: "luke"}.Encode(),
+		"bad value":    Cursor{Sort: "age", Values: []interface{}{"old"}, ID: "5f1d7f9c7d1e2a0001a1b2c3"}.Encode(),
+		"few values":   Cursor{Sort: "age", ID: "5f1d7f9c7d1e2a0001a1b2c3"}.Encode(),
+	}
+	for name, token := range tokens {
+		_, err := DecodeCursor(token, sort)
+		assert.NotNil(t, err, name)
+	}
+}
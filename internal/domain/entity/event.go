@@ -0,0 +1,67 @@
+package entity
+
+import "time"
+
+type UserEventType string
+
+const (
+	UserCreated         UserEventType = "user.created"
+	UserUpdated         UserEventType = "user.updated"
+	UserDeleted         UserEventType = "user.deleted"
+	UserPasswordChanged UserEventType = "user.password_changed"
+)
+
+// UserEvent notifies other services about a change in the lifecycle of a user,
+// the ID is assigned by the outbox and must be used by consumers to drop duplicates
+type UserEvent struct {
+	ID         string        `json:"id"`
+	Type       UserEventType `json:"type" enums:"user.created,user.updated,user.deleted,user.password_changed"`
+	UserID     string        `json:"userId"`
+	User       *User         `json:"user,omitempty"`
+	Fields     []string      `json:"fields"`
+	OccurredAt time.Time     `json:"occurredAt"`
+}
+
+func NewUserEvent(eventType UserEventType, user User, fields []string) UserEvent {
+	if fields == nil {
+		fields = []string{}
+	}
+	event := UserEvent{
+		Type:       eventType,
+		UserID:     user.ID,
+		Fields:     fields,
+		OccurredAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	if eventType != UserDeleted {
+		user.Password = "" // must never leave the service
+		event.User = &user
+	}
+	return event
+}
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxPublished OutboxStatus = "published"
+	OutboxDead      OutboxStatus = "dead"
+)
+
+// OutboxEntry tracks the delivery of an event stored with the change that emitted it
+type OutboxEntry struct {
+	Event         UserEvent    `json:"event"`
+	Status        OutboxStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	NextAttemptAt time.Time    `json:"nextAttemptAt"`
+	LastError     string       `json:"lastError,omitempty"`
+	PublishedAt   *time.Time   `json:"publishedAt,omitempty"`
+}
+
+func (t UserEventType) Valid() bool {
+	switch t {
+	case UserCreated, UserUpdated, UserDeleted, UserPasswordChanged:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UserFields are the fields a user can be read with, the password is never read
+var UserFields = []UserFied{ID, Name, Age, Email, Address}
+
+// ParseFields reads the names of the fields of a sparse user, id is the ID field
+func ParseFields(names []string) ([]UserFied, error) {
+	fields := []UserFied{}
+	seen := map[UserFied]bool{}
+	for _, name := range names {
+		field := UserFied(name)
+		switch field {
+		case "id":
+			field = ID
+		case Name, Age, Email, Address:
+		case "password":
+			return nil, errors.New("password can't be read")
+		default:
+			return nil, fmt.Errorf("invalid field %q, use id, name, age, email or address", name)
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// Select keeps only the fields of the user, it is the sparse form of the user. The
+// score of a text search is always kept
+func (u User) Select(fields []UserFied) map[string]interface{} {
+	selected := make(map[string]interface{}, len(fields)+1)
+	if u.Score != 0 {
+		selected[string(Score)] = u.Score
+	}
+	for _, field := range fields {
+		switch field {
+		case ID:
+			selected["id"] = u.ID
+		case Name, Age, Email, Address:
+			selected[string(field)] = u.SortValue(field)
+		}
+	}
+	return selected
+}
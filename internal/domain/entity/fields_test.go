@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields([]string{"id", "name", "email", "name"})
+	assert.Nil(t, err)
+	assert.Equal(t, []UserFied{ID, Name, Email}, fields)
+
+	_, err = ParseFields([]string{"name", "password"})
+	assert.NotNil(t, err, "the password must never be read")
+	_, err = ParseFields([]string{"_id"})
+	assert.NotNil(t, err)
+}
+
+func TestUserSelect(t *testing.T) {
+	user := User{ID: "1", Name: "Luke", Age: 24, Email: "luke@rebels.org", Password: "hash"}
+	assert.Equal(t, map[string]interface{}{"id": "1", "age": 24}, user.Select([]UserFied{ID, Age}))
+}
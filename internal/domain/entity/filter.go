@@ -1,8 +1,256 @@
 package entity
 
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
 type FilterOperation string
 
 const (
-	Equal FilterOperation = "="
-	Like  FilterOperation = "~"
+	Equal          FilterOperation = "="
+	Like           FilterOperation = "~"
+	NotEqual       FilterOperation = "!="
+	Greater        FilterOperation = ">"
+	GreaterOrEqual FilterOperation = ">="
+	Less           FilterOperation = "<"
+	LessOrEqual    FilterOperation = "<="
+	In             FilterOperation = "in"
+	NotIn          FilterOperation = "nin"
+	Exists         FilterOperation = "exists"
+	Prefix         FilterOperation = "prefix"
+	Contains       FilterOperation = "contains"
+	StartsWith     FilterOperation = "startsWith"
+	EndsWith       FilterOperation = "endsWith"
+	IEquals        FilterOperation = "iequals"
+	// Regex matches a raw regular expression, only privileged API tokens may use it
+	Regex FilterOperation = "regex"
+)
+
+const (
+	// MaxPatternLength bounds the text matched by the pattern operations
+	MaxPatternLength = 256
+	// MaxRegexLength bounds a raw regular expression
+	MaxRegexLength = 128
+	// maxRegexRepeat bounds the counted repetitions of a raw regular expression
+	maxRegexRepeat = 100
 )
+
+// FieldType is the type a filter value must have for a field
+type FieldType string
+
+const (
+	StringField FieldType = "string"
+	NumberField FieldType = "number"
+)
+
+// fieldOperations lists the operations allowed on each field type, pattern
+// operations only make sense on text
+var fieldOperations = map[FieldType][]FilterOperation{
+	StringField: {Equal, Like, NotEqual, Greater, GreaterOrEqual, Less, LessOrEqual, In, NotIn, Exists, Prefix,
+		Contains, StartsWith, EndsWith, IEquals, Regex},
+	NumberField: {Equal, NotEqual, Greater, GreaterOrEqual, Less, LessOrEqual, In, NotIn, Exists},
+}
+
+// Pattern reports whether the operation matches text, its value is escaped unless it is Regex
+func (op FilterOperation) Pattern() bool {
+	switch op {
+	case Like, Prefix, Contains, StartsWith, EndsWith, IEquals, Regex:
+		return true
+	default:
+		return false
+	}
+}
+
+func (op FilterOperation) allowedOn(fieldType FieldType) bool {
+	for _, allowed := range fieldOperations[fieldType] {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+// convertValue returns value as the go type of fieldType, numbers may come as
+// JSON numbers or as strings from a query string
+func convertValue(fieldType FieldType, value interface{}) (interface{}, error) {
+	switch fieldType {
+	case NumberField:
+		return convertNumber(value)
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return nil, errors.New("expects a string")
+		}
+		return text, nil
+	}
+}
+
+func convertNumber(value interface{}) (int, error) {
+	switch number := value.(type) {
+	case int:
+		return number, nil
+	case int32:
+		return int(number), nil
+	case int64:
+		return int(number), nil
+	case float64:
+		if number != math.Trunc(number) || math.IsInf(number, 0) {
+			return 0, errors.New("expects an integer")
+		}
+		return int(number), nil
+	case string:
+		converted, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, errors.New("expects an integer")
+		}
+		return converted, nil
+	default:
+		return 0, errors.New("expects an integer")
+	}
+}
+
+func convertBool(value interface{}) (bool, error) {
+	switch flag := value.(type) {
+	case bool:
+		return flag, nil
+	case string:
+		converted, err := strconv.ParseBool(flag)
+		if err == nil {
+			return converted, nil
+		}
+	}
+	return false, errors.New("expects true or false")
+}
+
+// convertList converts every element of a list, a single value is a list of one
+func convertList(fieldType FieldType, value interface{}) ([]interface{}, error) {
+	var values []interface{}
+	switch list := value.(type) {
+	case []interface{}:
+		values = list
+	case []string:
+		for _, v := range list {
+			values = append(values, v)
+		}
+	case []int:
+		for _, v := range list {
+			values = append(values, v)
+		}
+	default:
+		values = []interface{}{value}
+	}
+	if len(values) == 0 {
+		return nil, errors.New("expects at least one value")
+	}
+	converted := make([]interface{}, 0, len(values))
+	for i, v := range values {
+		c, err := convertValue(fieldType, v)
+		if err != nil {
+			return nil, fmt.Errorf("value %d %v", i, err)
+		}
+		converted = append(converted, c)
+	}
+	return converted, nil
+}
+
+// checkPattern limits the text of pattern operations, a raw regex must also be free of
+// nested repetitions and repeated alternations, the sources of catastrophic backtracking
+func checkPattern(op FilterOperation, pattern string) error {
+	if op != Regex {
+		if len(pattern) > MaxPatternLength {
+			return fmt.Errorf("is longer than %d characters", MaxPatternLength)
+		}
+		return nil
+	}
+	if len(pattern) > MaxRegexLength {
+		return fmt.Errorf("is longer than %d characters", MaxRegexLength)
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return errors.New("is not a valid regular expression")
+	}
+	if repeatsAlternation(pattern) {
+		return errors.New("repeats an alternation")
+	}
+	return checkRegex(parsed, false)
+}
+
+// repeatsAlternation scans the raw pattern for a repeated group holding a |, the parser
+// folds alternations like (a|a) or (\w|\d) into a class that no longer shows them
+func repeatsAlternation(pattern string) bool {
+	// whether each open group holds an alternation, directly or in a nested group
+	groups := []bool{}
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '\\':
+			i++ // the escaped character is a literal
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+			// a ] right after the [ or [^ is a literal
+			if strings.HasPrefix(pattern[i+1:], "^") {
+				i++
+			}
+			if strings.HasPrefix(pattern[i+1:], "]") {
+				i++
+			}
+		case c == '(':
+			groups = append(groups, false)
+		case c == '|' && len(groups) > 0:
+			groups[len(groups)-1] = true
+		case c == ')' && len(groups) > 0:
+			alternation := groups[len(groups)-1]
+			groups = groups[:len(groups)-1]
+			if alternation && repeated(pattern[i+1:]) {
+				return true
+			}
+			if alternation && len(groups) > 0 {
+				groups[len(groups)-1] = true
+			}
+		}
+	}
+	return false
+}
+
+// repeated reports whether what follows a group starts with a quantifier
+func repeated(rest string) bool {
+	if rest == "" {
+		return false
+	}
+	if strings.IndexByte("*+?", rest[0]) >= 0 {
+		return true
+	}
+	return rest[0] == '{' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9'
+}
+
+func checkRegex(re *syntax.Regexp, repeated bool) error {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		if repeated {
+			return errors.New("nests repetitions")
+		}
+		if re.Op == syntax.OpRepeat && re.Max > maxRegexRepeat {
+			return fmt.Errorf("repeats more than %d times", maxRegexRepeat)
+		}
+		repeated = true
+	case syntax.OpAlternate:
+		// alternatives that overlap, like (a|aa)*, backtrack like nested repetitions
+		if repeated {
+			return errors.New("repeats an alternation")
+		}
+	}
+	for _, sub := range re.Sub {
+		err := checkRegex(sub, repeated)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package entity
+
+// MaxFuzzyLength bounds the text of a fuzzy search
+const MaxFuzzyLength = 100
+
+// FuzzySearch looks users up by a misspelled name or email. Users are ranked by their
+// similarity to Text, from 0 to 1, and only those at least as similar as Threshold are
+// kept. Candidates bounds the users compared, they are the users sharing a trigram with Text
+type FuzzySearch struct {
+	Text       string
+	Threshold  float64
+	Limit      int
+	Candidates int
+}
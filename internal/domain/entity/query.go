@@ -0,0 +1,176 @@
+package entity
+
+import (
+	"fmt"
+)
+
+const (
+	// MaxQueryDepth bounds the nesting of groups, a single filter has depth 1
+	MaxQueryDepth = 5
+	// MaxQueryNodes bounds the number of groups and filters of an expression
+	MaxQueryNodes = 50
+	// MaxTextLength bounds the words of a text search
+	MaxTextLength = 256
+)
+
+// UserQuery is a filter expression, each node sets exactly one of and, or, not
+// or filter. The empty query matches every user. Text, a full text search over
+// the name, email and address, is only read from the root node
+type UserQuery struct {
+	And    []UserQuery `json:"and,omitempty"`
+	Or     []UserQuery `json:"or,omitempty"`
+	Not    *UserQuery  `json:"not,omitempty"`
+	Filter *UserFilter `json:"filter,omitempty"`
+	Text   string      `json:"-"`
+}
+
+// CheckText validates the words of a text search
+func CheckText(text string) error {
+	if len(text) > MaxTextLength {
+		return fmt.Errorf("text has %d characters, at most %d are allowed", len(text), MaxTextLength)
+	}
+	return nil
+}
+
+// AllOf is the query matching the users that pass every filter
+func AllOf(filters ...UserFilter) UserQuery {
+	if len(filters) == 0 {
+		return UserQuery{}
+	}
+	query := UserQuery{And: make([]UserQuery, 0, len(filters))}
+	for i := range filters {
+		query.And = append(query.And, UserQuery{Filter: &filters[i]})
+	}
+	return query
+}
+
+func (q UserQuery) IsEmpty() bool {
+	return q.And == nil && q.Or == nil && q.Not == nil && q.Filter == nil
+}
+
+// Errors validates the expression, the problems are keyed by the path of the
+// offending node, like query.or[1].not.filter
+func (q UserQuery) Errors(path string) map[string]interface{} {
+	errors := map[string]interface{}{}
+	if q.IsEmpty() {
+		return errors
+	}
+	if nodes := q.nodes(); nodes > MaxQueryNodes {
+		errors[path] = fmt.Sprintf("query has %d nodes, at most %d are allowed", nodes, MaxQueryNodes)
+		return errors
+	}
+	q.validate(path, 1, errors)
+	return errors
+}
+
+func (q UserQuery) validate(path string, depth int, errors map[string]interface{}) {
+	if depth > MaxQueryDepth {
+		errors[path] = fmt.Sprintf("query is nested deeper than %d levels", MaxQueryDepth)
+		return
+	}
+	set := 0
+	for _, isSet := range []bool{q.And != nil, q.Or != nil, q.Not != nil, q.Filter != nil} {
+		if isSet {
+			set++
+		}
+	}
+	if set != 1 {
+		errors[path] = "node must have exactly one of and, or, not, filter"
+		return
+	}
+
+	switch {
+	case q.Filter != nil:
+		err := q.Filter.Validate()
+		if err != nil {
+			errors[path+".filter"] = err.Error()
+		}
+	case q.Not != nil:
+		q.Not.validate(path+".not", depth+1, errors)
+	default:
+		name, children := "and", q.And
+		if q.Or != nil {
+			name, children = "or", q.Or
+		}
+		if len(children) == 0 {
+			errors[path+"."+name] = name + " requires at least one node"
+		}
+		for i, child := range children {
+			child.validate(fmt.Sprintf("%s.%s[%d]", path, name, i), depth+1, errors)
+		}
+	}
+}
+
+func (q UserQuery) nodes() int {
+	count := 1
+	for _, child := range q.And {
+		count += child.nodes()
+	}
+	for _, child := range q.Or {
+		count += child.nodes()
+	}
+	if q.Not != nil {
+		count += q.Not.nodes()
+	}
+	return count
+}
+
+// Normalize converts the value of every filter to the type of its field, the query must be valid
+func (q UserQuery) Normalize() (UserQuery, error) {
+	normalized := UserQuery{Text: q.Text}
+	var err error
+	if q.Filter != nil {
+		filter, err := q.Filter.Normalize()
+		if err != nil {
+			return q, err
+		}
+		normalized.Filter = &filter
+	}
+	if q.Not != nil {
+		not, err := q.Not.Normalize()
+		if err != nil {
+			return q, err
+		}
+		normalized.Not = &not
+	}
+	normalized.And, err = normalizeAll(q.And)
+	if err != nil {
+		return q, err
+	}
+	normalized.Or, err = normalizeAll(q.Or)
+	if err != nil {
+		return q, err
+	}
+	return normalized, nil
+}
+
+func normalizeAll(queries []UserQuery) ([]UserQuery, error) {
+	if queries == nil {
+		return nil, nil
+	}
+	normalized := make([]UserQuery, 0, len(queries))
+	for _, query := range queries {
+		n, err := query.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+	return normalized, nil
+}
+
+// Uses reports whether any filter of the query has the operation
+func (q UserQuery) Uses(op FilterOperation) bool {
+	if q.Filter != nil && q.Filter.Operator == op {
+		return true
+	}
+	if q.Not != nil && q.Not.Uses(op) {
+		return true
+	}
+	for _, child := range append(append([]UserQuery{}, q.And...), q.Or...) {
+		if child.Uses(op) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserQueryErrorsPointAtNodes(t *testing.T) {
+	query := UserQuery{Or: []UserQuery{
+		{Filter: &UserFilter{Field: "name", Operator: "=", Value: "Luke"}},
+		{Not: &UserQuery{Filter: &UserFilter{Field: "age", Operator: "~", Value: "4"}}},
+		{And: []UserQuery{}},
+		{Filter: &UserFilter{Field: "name", Operator: "=", Value: "Leia"}, Not: &UserQuery{}},
+	}}
+
+	assert.Equal(t, map[string]interface{}{
+		"query.or[1].not.filter": "invalid operator ~ for age",
+		"query.or[2].and":        "and requires at least one node",
+		"query.or[3]":            "node must have exactly one of and, or, not, filter",
+	}, query.Errors("query"))
+	assert.Empty(t, UserQuery{}.Errors("query"), "the empty query matches everyone")
+	assert.Empty(t, AllOf(UserFilter{Field: "age", Operator: ">", Value: 18.0}).Errors("query"))
+}
+
+func TestUserQueryLimits(t *testing.T) {
+	leaf := UserQuery{Filter: &UserFilter{Field: "name", Operator: "=", Value: "Luke"}}
+	deep := leaf
+	for i := 0; i < MaxQueryDepth; i++ {
+		inner := deep
+		deep = UserQuery{Not: &inner}
+	}
+	assert.Contains(t, deep.Errors("query"), "query.not.not.not.not.not")
+
+	wide := UserQuery{}
+	for i := 0; i < MaxQueryNodes; i++ {
+		wide.Or = append(wide.Or, leaf)
+	}
+	assert.Contains(t, wide.Errors("query"), "query")
+}
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortRule orders the users by a field
+type SortRule struct {
+	Field      UserFied
+	Descending bool
+}
+
+// Sortable reports whether users may be sorted by the field
+func (f UserFied) Sortable() bool {
+	switch f {
+	case Name, Age, Email, Address, Score:
+		return true
+	default:
+		return false
+	}
+}
+
+// ParseSortRule parses a field name, descending when it starts with - and ascending
+// when it has no sign or starts with +
+func ParseSortRule(rule string) (SortRule, error) {
+	parsed := SortRule{}
+	switch {
+	case strings.HasPrefix(rule, "-"):
+		parsed.Descending = true
+		rule = rule[1:]
+	case strings.HasPrefix(rule, "+"):
+		rule = rule[1:]
+	}
+	parsed.Field = UserFied(rule)
+	if !parsed.Field.Sortable() {
+		return parsed, fmt.Errorf("invalid sort field %q, use name, age, email, address or score", rule)
+	}
+	return parsed, nil
+}
+
+// ParseSort parses every rule, the problems are keyed by the index of the rule like sort1
+func ParseSort(rules []string) ([]SortRule, map[string]interface{}) {
+	parsed := make([]SortRule, 0, len(rules))
+	errors := map[string]interface{}{}
+	for i, rule := range rules {
+		sortRule, err := ParseSortRule(rule)
+		if err != nil {
+			errors[fmt.Sprintf("sort%d", i)] = err.Error()
+			continue
+		}
+		parsed = append(parsed, sortRule)
+	}
+	return parsed, errors
+}
+
+// SortsByScore reports whether the rules sort by relevance, which only text searches can do
+func SortsByScore(rules []SortRule) bool {
+	for _, rule := range rules {
+		if rule.Field == Score {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSortRule(t *testing.T) {
+	rule, err := ParseSortRule("-name")
+	assert.Nil(t, err)
+	assert.Equal(t, SortRule{Field: Name, Descending: true}, rule)
+
+	rule, err = ParseSortRule("+age")
+	assert.Nil(t, err)
+	assert.Equal(t, SortRule{Field: Age}, rule)
+
+	for _, invalid := range []string{"password", "id", "na-me", "--name", "", "Name"} {
+		_, err = ParseSortRule(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	rules, errors := ParseSort([]string{"email", "password", "-address"})
+	assert.Equal(t, []SortRule{{Field: Email}, {Field: Address, Descending: true}}, rules)
+	assert.Len(t, errors, 1)
+	assert.Contains(t, errors, "sort1")
+}
+
+func TestSortsByScore(t *testing.T) {
+	assert.True(t, SortsByScore([]SortRule{{Field: Name}, {Field: Score}}))
+	assert.False(t, SortsByScore([]SortRule{{Field: Name}}))
+	_, err := DecodeCursor(NewCursor(User{ID: "5f1d7f9c7d1e2a0001a1b2c3"}, []SortRule{{Field: Score}}, false).Encode(), []SortRule{{Field: Score}})
+	assert.NotNil(t, err, "scores can't be paginated by cursor")
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/Shodocan/UserService/internal/configs/engine"
@@ -13,6 +14,8 @@ type User struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 	Address  string `json:"address"`
+	// Score is the relevance of the user to a text or fuzzy search, it is only set by those searches
+	Score float64 `json:"score,omitempty"`
 }
 
 func (u User) Validate(creation bool) error {
@@ -40,28 +43,85 @@ const (
 	Age     UserFied = "age"
 	Email   UserFied = "email"
 	Address UserFied = "address"
+	// Score sorts the users of a text search by relevance, most relevant first
+	Score UserFied = "score"
 )
 
+// Type is the type of the values a field is filtered by, false when the field can't be filtered
+func (f UserFied) Type() (FieldType, bool) {
+	switch f {
+	case Name, Address, Email:
+		return StringField, true
+	case Age:
+		return NumberField, true
+	default:
+		return "", false
+	}
+}
+
+// UserFilter compares a field with Value, in and nin take a list and exists takes a boolean.
+// Pattern operations match Value literally, except regex, IgnoreCase makes them case insensitive
 type UserFilter struct {
-	Field    UserFied `enums:"name,age,email,address"`
-	Value    interface{}
-	Operator FilterOperation `enums:"=,~"`
+	Field      UserFied `enums:"name,age,email,address"`
+	Value      interface{}
+	Operator   FilterOperation `enums:"=,~,!=,>,>=,<,<=,in,nin,exists,prefix,contains,startsWith,endsWith,iequals,regex"`
+	IgnoreCase bool            `json:",omitempty"`
 }
 
 func (f UserFilter) Valid() bool {
-	switch f.Field {
-	case Name, Age, Address, Email:
-		break
-	default:
-		return false
+	return f.Validate() == nil
+}
+
+func (f UserFilter) Validate() error {
+	_, err := f.Normalize()
+	return err
+}
+
+// Normalize validates the filter and returns it with Value converted to the type of the field
+func (f UserFilter) Normalize() (UserFilter, error) {
+	fieldType, ok := f.Field.Type()
+	if !ok {
+		return f, fmt.Errorf("invalid field %s", f.Field)
+	}
+	if !f.Operator.allowedOn(fieldType) {
+		return f, fmt.Errorf("invalid operator %s for %s", f.Operator, f.Field)
 	}
 
+	var err error
 	switch f.Operator {
-	case Equal, Like:
-		break
+	case Exists:
+		f.Value, err = convertBool(f.Value)
+	case In, NotIn:
+		f.Value, err = convertList(fieldType, f.Value)
 	default:
-		return false
+		f.Value, err = convertValue(fieldType, f.Value)
+	}
+	if err == nil && f.Operator.Pattern() {
+		err = checkPattern(f.Operator, f.Value.(string))
+	}
+	if err != nil {
+		return f, fmt.Errorf("%s %s %v", f.Field, f.Operator, err)
 	}
+	return f, nil
+}
 
-	return true
+// ChangedFields lists the names of the fields that differ between two versions of a user
+func ChangedFields(before, after User) []string {
+	fields := []string{}
+	if before.Name != after.Name {
+		fields = append(fields, string(Name))
+	}
+	if before.Age != after.Age {
+		fields = append(fields, string(Age))
+	}
+	if before.Email != after.Email {
+		fields = append(fields, string(Email))
+	}
+	if before.Password != after.Password {
+		fields = append(fields, "password")
+	}
+	if before.Address != after.Address {
+		fields = append(fields, string(Address))
+	}
+	return fields
 }
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,76 @@ func TestUserFilterValidation(t *testing.T) {
 	assert.False(t, filter9.Valid())
 }
 
+func TestUserFilterOperators(t *testing.T) {
+	valid := []UserFilter{
+		{Field: "age", Operator: ">", Value: 18.0},
+		{Field: "age", Operator: "<=", Value: "30"},
+		{Field: "age", Operator: "!=", Value: 20},
+		{Field: "age", Operator: "in", Value: []interface{}{18.0, "21"}},
+		{Field: "email", Operator: "nin", Value: []string{"a@b.com"}},
+		{Field: "address", Operator: "exists", Value: "false"},
+		{Field: "name", Operator: "prefix", Value: "Wal"},
+		{Field: "name", Operator: ">=", Value: "M"},
+	}
+	for _, filter := range valid {
+		assert.Nil(t, filter.Validate(), "%s %s %v", filter.Field, filter.Operator, filter.Value)
+	}
+
+	invalid := []UserFilter{
+		{Field: "age", Operator: ">", Value: 18.5},
+		{Field: "age", Operator: "=", Value: "eighteen"},
+		{Field: "age", Operator: "prefix", Value: "1"},
+		{Field: "age", Operator: "in", Value: []interface{}{}},
+		{Field: "email", Operator: "=", Value: 12.0},
+		{Field: "email", Operator: "exists", Value: "maybe"},
+		{Field: "name", Operator: "between", Value: "a"},
+	}
+	for _, filter := range invalid {
+		assert.NotNil(t, filter.Validate(), "%s %s %v", filter.Field, filter.Operator, filter.Value)
+	}
+
+	filter, err := UserFilter{Field: "age", Operator: "in", Value: []interface{}{18.0, "21"}}.Normalize()
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{18, 21}, filter.Value)
+}
+
+func TestUserFilterPatternLimits(t *testing.T) {
+	valid := []UserFilter{
+		{Field: "name", Operator: "contains", Value: "sky", IgnoreCase: true},
+		{Field: "email", Operator: "iequals", Value: "LUKE@REBELS.ORG"},
+		{Field: "name", Operator: "regex", Value: `^(Luke|Leia) [A-Z]\w+$`},
+		{Field: "name", Operator: "regex", Value: `a{1,100}`},
+		{Field: "name", Operator: "regex", Value: `^[ab]+$`},
+		{Field: "name", Operator: "regex", Value: `^[|(]+ \(a\|b\)+$`},
+		{Field: "name", Operator: "regex", Value: `(a|b)c+`},
+	}
+	for _, filter := range valid {
+		assert.Nil(t, filter.Validate(), "%s %v", filter.Operator, filter.Value)
+	}
+
+	invalid := []UserFilter{
+		{Field: "name", Operator: "~", Value: strings.Repeat("a", MaxPatternLength+1)},
+		{Field: "name", Operator: "regex", Value: strings.Repeat("a", MaxRegexLength+1)},
+		{Field: "name", Operator: "regex", Value: `(a+)+$`},
+		{Field: "name", Operator: "regex", Value: `(\w*\s?)*x`},
+		{Field: "name", Operator: "regex", Value: `(a|aa)*$`},
+		{Field: "name", Operator: "regex", Value: `^(x|xy|xyz)+$`},
+		{Field: "name", Operator: "regex", Value: `^(a|b)+$`},
+		{Field: "name", Operator: "regex", Value: `(a|a)*$`},
+		{Field: "name", Operator: "regex", Value: `(\w|\d)+$`},
+		{Field: "name", Operator: "regex", Value: `(x|x)*y`},
+		{Field: "name", Operator: "regex", Value: `([a-z]|\w)+!`},
+		{Field: "name", Operator: "regex", Value: `(?:x(a|a)){2,5}`},
+		{Field: "name", Operator: "regex", Value: `a{1,1000}`},
+		{Field: "name", Operator: "regex", Value: `(a`},
+		{Field: "name", Operator: "regex", Value: `(?=a)`},
+		{Field: "age", Operator: "contains", Value: "1"},
+	}
+	for _, filter := range invalid {
+		assert.NotNil(t, filter.Validate(), "%s %v", filter.Operator, filter.Value)
+	}
+}
+
 func TestUserValidation(t *testing.T) {
 	user1 := User{Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "123"}
 	user2 := User{Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com"}
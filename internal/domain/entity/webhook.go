@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs/engine"
+)
+
+// WebhookSubscription registers an URL to be called on user events, no event
+// types means every event
+type WebhookSubscription struct {
+	ID         string          `json:"id"`
+	URL        string          `json:"url" example:"https://example.com/hooks/users"`
+	EventTypes []UserEventType `json:"eventTypes" enums:"user.created,user.updated,user.deleted,user.password_changed"`
+	Secret     string          `json:"secret,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+func (s WebhookSubscription) Validate() error {
+	validationErrors := map[string]interface{}{}
+	target, err := url.Parse(s.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		validationErrors["url"] = "A valid http(s) url is required"
+	}
+	for i, eventType := range s.EventTypes {
+		if !eventType.Valid() {
+			validationErrors[fmt.Sprintf("eventTypes%d", i)] = fmt.Sprintf("Invalid event type: %s", eventType)
+		}
+	}
+	if len(validationErrors) > 0 {
+		return engine.NewGenericError(http.StatusBadRequest, "Invalid Webhook").ExtraData(validationErrors)
+	}
+	return nil
+}
+
+func (s WebhookSubscription) Matches(eventType UserEventType) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, subscribed := range s.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+// WebhookDelivery is the history of sending one event to one subscription,
+// dead deliveries form the dead-letter list
+type WebhookDelivery struct {
+	ID             string         `json:"id"`
+	SubscriptionID string         `json:"subscriptionId"`
+	EventID        string         `json:"eventId"`
+	EventType      UserEventType  `json:"eventType"`
+	Payload        string         `json:"payload"`
+	Status         DeliveryStatus `json:"status" enums:"pending,delivered,dead"`
+	Attempts       int            `json:"attempts"`
+	NextAttemptAt  time.Time      `json:"nextAttemptAt"`
+	LastStatusCode int            `json:"lastStatusCode,omitempty"`
+	LastError      string         `json:"lastError,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	DeliveredAt    *time.Time     `json:"deliveredAt,omitempty"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookValidation(t *testing.T) {
+	assert.Nil(t, WebhookSubscription{URL: "https://example.com/hooks"}.Validate())
+	assert.Nil(t, WebhookSubscription{URL: "http://localhost:9000", EventTypes: []UserEventType{UserCreated}}.Validate())
+	assert.NotNil(t, WebhookSubscription{URL: "ftp://example.com"}.Validate())
+	assert.NotNil(t, WebhookSubscription{URL: "example.com"}.Validate())
+	assert.NotNil(t, WebhookSubscription{URL: "https://example.com", EventTypes: []UserEventType{"user.renamed"}}.Validate())
+}
+
+func TestWebhookMatches(t *testing.T) {
+	all := WebhookSubscription{}
+	created := WebhookSubscription{EventTypes: []UserEventType{UserCreated}}
+
+	assert.True(t, all.Matches(UserDeleted))
+	assert.True(t, created.Matches(UserCreated))
+	assert.False(t, created.Matches(UserDeleted))
+}
@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"log"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+)
+
+type AuditCase struct {
+	config  *configs.EnvVarConfig
+	service services.AuditService
+	log     *log.Logger
+}
+
+func NewAuditCase(config *configs.EnvVarConfig,
+	service services.AuditService,
+	log *log.Logger,
+) *AuditCase {
+	return &AuditCase{config: config, service: service, log: log}
+}
+
+func (cs AuditCase) Search(filter entity.AuditFilter, limit, page int) ([]entity.AuditEvent, engine.Pagination, error) {
+	events, paginate, err := cs.service.Query(filter, page, limit)
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.Pagination{}, engine.ErrInternalFailure()
+	}
+	return events, paginate, nil
+}
+
+func (cs AuditCase) UserHistory(id string, limit, page int) ([]entity.AuditEvent, engine.Pagination, error) {
+	return cs.Search(entity.AuditFilter{UserID: id}, limit, page)
+}
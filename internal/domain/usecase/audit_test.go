@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditUserHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	events := []entity.AuditEvent{{ID: "1", UserID: "123", Action: entity.AuditCreate}}
+	pagination := engine.NewPagination(1, 1, 1, 10)
+
+	auditMock := services.NewMockAuditService(ctrl)
+	auditMock.EXPECT().Query(entity.AuditFilter{UserID: "123"}, 1, 10).Return(events, pagination, nil)
+
+	retreivedEvents, page, err := NewAuditCase(getConfig(t), auditMock, configs.NewLog()).UserHistory("123", 10, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, pagination, page)
+	assert.Equal(t, events, retreivedEvents)
+}
+
+func TestAuditSearchError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	auditMock := services.NewMockAuditService(ctrl)
+	auditMock.EXPECT().Query(gomock.Any(), 1, 10).Return(nil, engine.Pagination{}, fmt.Errorf("adfasdf"))
+
+	retreivedEvents, _, err := NewAuditCase(getConfig(t), auditMock, configs.NewLog()).Search(entity.AuditFilter{Actor: "admin"}, 10, 1)
+	assert.NotNil(t, err)
+	assert.Len(t, retreivedEvents, 0)
+}
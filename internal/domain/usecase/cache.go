@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+)
+
+// cacheNamespace keeps the namespace from escaping its keys, ':' and glob characters are refused
+var cacheNamespace = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type CacheCase struct {
+	config  *configs.EnvVarConfig
+	service services.CacheService
+	log     *log.Logger
+}
+
+func NewCacheCase(config *configs.EnvVarConfig,
+	service services.CacheService,
+	log *log.Logger,
+) *CacheCase {
+	return &CacheCase{config: config, service: service, log: log}
+}
+
+// Flush drops the cache of a namespace, returning how many keys were deleted
+func (cs CacheCase) Flush(namespace string) (int64, error) {
+	if !cacheNamespace.MatchString(namespace) {
+		return 0, engine.ErrBadRequest().Message("Invalid namespace")
+	}
+	deleted, err := cs.service.Flush(namespace)
+	if err != nil {
+		return deleted, cs.failure(err)
+	}
+	cs.log.Printf("cache of namespace %s flushed, %d keys deleted", namespace, deleted)
+	return deleted, nil
+}
+
+func (cs CacheCase) Stats() (entity.CacheStats, error) {
+	stats, err := cs.service.Stats()
+	return stats, cs.failure(err)
+}
+
+// InspectUser shows what the cache holds for a user, it never loads the user
+func (cs CacheCase) InspectUser(id string) (entity.CacheEntry, error) {
+	entry, err := cs.service.InspectUser(id)
+	return entry, cs.failure(err)
+}
+
+func (cs CacheCase) EvictUser(id string) error {
+	return cs.failure(cs.service.EvictUser(id))
+}
+
+func (cs CacheCase) failure(err error) error {
+	if err == nil {
+		return nil
+	}
+	if err == services.ErrCacheDisabled {
+		return engine.NewGenericError(http.StatusNotImplemented, "Cache Disabled")
+	}
+	cs.log.Println(err)
+	return engine.ErrInternalFailure()
+}
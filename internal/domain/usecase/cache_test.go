@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheFlush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheMock := services.NewMockCacheService(ctrl)
+	cacheMock.EXPECT().Flush("users").Return(int64(3), nil)
+
+	deleted, err := NewCacheCase(getConfig(t), cacheMock, configs.NewLog()).Flush("users")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
+
+func TestCacheFlushErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheMock := services.NewMockCacheService(ctrl)
+	useCase := NewCacheCase(getConfig(t), cacheMock, configs.NewLog())
+
+	_, err := useCase.Flush("users:*")
+	assert.Equal(t, http.StatusBadRequest, err.(*engine.Error).Code)
+
+	cacheMock.EXPECT().Flush("users").Return(int64(0), services.ErrCacheDisabled)
+	_, err = useCase.Flush("users")
+	assert.Equal(t, http.StatusNotImplemented, err.(*engine.Error).Code)
+
+	cacheMock.EXPECT().Flush("users").Return(int64(0), fmt.Errorf("redis down"))
+	_, err = useCase.Flush("users")
+	assert.Equal(t, http.StatusInternalServerError, err.(*engine.Error).Code)
+}
+
+func TestCacheInspectAndEvictUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cacheMock := services.NewMockCacheService(ctrl)
+	useCase := NewCacheCase(getConfig(t), cacheMock, configs.NewLog())
+
+	cached := entity.CacheEntry{Key: "userservice:users:id:123", Cached: true}
+	cacheMock.EXPECT().InspectUser("123").Return(cached, nil)
+	entry, err := useCase.InspectUser("123")
+	assert.Nil(t, err)
+	assert.Equal(t, cached, entry)
+
+	cacheMock.EXPECT().EvictUser("123").Return(nil)
+	assert.Nil(t, useCase.EvictUser("123"))
+
+	cacheMock.EXPECT().Stats().Return(entity.CacheStats{}, services.ErrCacheDisabled)
+	_, err = useCase.Stats()
+	assert.Equal(t, http.StatusNotImplemented, err.(*engine.Error).Code)
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"log"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+)
+
+type EventCase struct {
+	config *configs.EnvVarConfig
+	outbox services.OutboxService
+	log    *log.Logger
+}
+
+func NewEventCase(config *configs.EnvVarConfig,
+	outbox services.OutboxService,
+	log *log.Logger,
+) *EventCase {
+	return &EventCase{config: config, outbox: outbox, log: log}
+}
+
+// Poll reads the user events persisted after lastEventID, or after since when it is empty
+func (cs EventCase) Poll(lastEventID string, since time.Time, types []entity.UserEventType, limit int) ([]entity.UserEvent, error) {
+	events, err := cs.outbox.Events(lastEventID, since, types, limit)
+	if err == services.ErrInvalidEventID {
+		return nil, engine.ErrBadRequest().Message("Invalid Last-Event-ID")
+	}
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.ErrInternalFailure()
+	}
+	return events, nil
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventPoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	since := time.Now()
+	types := []entity.UserEventType{entity.UserCreated}
+	events := []entity.UserEvent{{ID: "2", Type: entity.UserCreated, UserID: "123"}}
+
+	outboxMock := services.NewMockOutboxService(ctrl)
+	outboxMock.EXPECT().Events("1", since, types, 10).Return(events, nil)
+
+	retreivedEvents, err := NewEventCase(getConfig(t), outboxMock, configs.NewLog()).Poll("1", since, types, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, events, retreivedEvents)
+}
+
+func TestEventPollErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outboxMock := services.NewMockOutboxService(ctrl)
+	outboxMock.EXPECT().Events("bad", gomock.Any(), gomock.Any(), 10).Return(nil, services.ErrInvalidEventID)
+	outboxMock.EXPECT().Events("", gomock.Any(), gomock.Any(), 10).Return(nil, fmt.Errorf("adfasdf"))
+
+	useCase := NewEventCase(getConfig(t), outboxMock, configs.NewLog())
+	_, err := useCase.Poll("bad", time.Now(), nil, 10)
+	assert.Equal(t, http.StatusBadRequest, err.(*engine.Error).Code)
+	_, err = useCase.Poll("", time.Now(), nil, 10)
+	assert.Equal(t, http.StatusInternalServerError, err.(*engine.Error).Code)
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"log"
+	"net/http"
 
 	"github.com/Shodocan/UserService/internal/configs"
 	"github.com/Shodocan/UserService/internal/configs/engine"
@@ -13,21 +14,122 @@ import (
 type UserCase struct {
 	config  *configs.EnvVarConfig
 	service services.UserService
+	auditor services.AuditService
+	actor   entity.AuditContext
 	log     *log.Logger
 }
 
 func NewUserCase(config *configs.EnvVarConfig,
 	service services.UserService,
+	auditor services.AuditService,
 	log *log.Logger,
 ) *UserCase {
-	return &UserCase{config: config, service: service, log: log}
+	return &UserCase{config: config, service: service, auditor: auditor, log: log}
 }
 
-func (cs UserCase) Search(filters []entity.UserFilter, sort []string, limit int, page int) ([]entity.User, engine.Pagination, error) {
-	usrs, paginate, err := cs.service.Query(filters, sort, page, limit)
+// As returns a copy of the use case recording its operations on behalf of actor
+func (cs UserCase) As(actor entity.AuditContext) UserCase {
+	cs.actor = actor
+	return cs
+}
+
+func (cs UserCase) record(action entity.AuditAction, userID string, fields []string, success bool) {
+	// audit failures are logged but never fail the operation itself
+	_, err := cs.auditor.Record(entity.NewAuditEvent(cs.actor, action, userID, fields, success))
+	if err != nil {
+		cs.log.Printf("failed to record audit event %s for %s: %v", action, userID, err)
+	}
+}
+
+func (cs UserCase) recordChanges(action entity.AuditAction, before, after entity.User) {
+	fields := entity.ChangedFields(before, after)
+	cs.record(action, after.ID, fields, true)
+	if before.Password != after.Password {
+		cs.record(entity.AuditPasswordChange, after.ID, []string{"password"}, true)
+	}
+}
+
+// emitChanges adds the events describing an update to the outbox of the transaction
+func (cs UserCase) emitChanges(tx services.UserService, before, after entity.User) error {
+	err := tx.AddEvent(entity.NewUserEvent(entity.UserUpdated, after, entity.ChangedFields(before, after)))
+	if err != nil {
+		return err
+	}
+	if before.Password != after.Password {
+		return tx.AddEvent(entity.NewUserEvent(entity.UserPasswordChanged, after, []string{"password"}))
+	}
+	return nil
+}
+
+// undoOnFailure undoes a change whose events could not be stored. Inside a transaction the undo is
+// rolled back with the change. With MongoDBTransactions off nothing is atomic, the undo is a best
+// effort to keep the change from persisting without its events. The error is returned either way
+func (cs UserCase) undoOnFailure(err error, undo func() error) error {
+	if err == nil {
+		return nil
+	}
+	if undoErr := undo(); undoErr != nil {
+		cs.log.Printf("failed to undo a change whose event was lost: %v", undoErr)
+	}
+	return err
+}
+
+// privileged reports whether the actor authenticated with a privileged API token
+func (cs UserCase) privileged() bool {
+	return cs.actor.Privileged
+}
+
+// Search lists a page of the users, skipTotal leaves them uncounted and fields, when given,
+// are the only ones read
+func (cs UserCase) Search(query entity.UserQuery, sort []entity.SortRule, limit int, page int, skipTotal bool, fields ...entity.UserFied) ([]entity.User, engine.Pagination, error) {
+	return cs.search(query, func() ([]entity.User, engine.Pagination, error) {
+		return cs.service.Query(query, sort, page, limit, skipTotal, fields...)
+	})
+}
+
+// Scroll is Search paginated by a cursor from a previous page instead of a page number
+func (cs UserCase) Scroll(query entity.UserQuery, sort []entity.SortRule, cursor entity.Cursor, limit int, skipTotal bool, fields ...entity.UserFied) ([]entity.User, engine.Pagination, error) {
+	return cs.search(query, func() ([]entity.User, engine.Pagination, error) {
+		return cs.service.Scroll(query, sort, cursor, limit, skipTotal, fields...)
+	})
+}
+
+// Fuzzy looks the users up by a misspelled name or email, most similar first
+func (cs UserCase) Fuzzy(search entity.FuzzySearch) ([]entity.User, error) {
+	usrs, err := cs.service.Fuzzy(search)
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.ErrInternalFailure()
+	}
+
+	for i := range usrs {
+		usrs[i].Password = ""
+	}
+	return usrs, nil
+}
+
+// Aggregate counts the users matching the query by the grouping
+func (cs UserCase) Aggregate(aggregation entity.UserAggregation) ([]entity.UserGroup, error) {
+	if aggregation.Query.Uses(entity.Regex) && !cs.privileged() {
+		return nil, engine.NewGenericError(http.StatusForbidden, "Regex filters require a privileged API token")
+	}
+	groups, err := cs.service.Aggregate(aggregation)
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.ErrInternalFailure()
+	}
+	return groups, nil
+}
+
+func (cs UserCase) search(query entity.UserQuery, list func() ([]entity.User, engine.Pagination, error)) ([]entity.User, engine.Pagination, error) {
+	// a raw regex can still be slow, it is kept to trusted clients
+	if query.Uses(entity.Regex) && !cs.privileged() {
+		return nil, engine.Pagination{}, engine.NewGenericError(http.StatusForbidden, "Regex filters require a privileged API token")
+	}
+	usrs, paginate, err := list()
 	if err != nil {
 		cs.log.Println(err)
-		return nil, engine.Pagination{Pages: 0, Total: 0, PageSize: 0}, engine.ErrInternalFailure()
+		return nil, engine.Pagination{}, engine.ErrInternalFailure()
 	}
 
 	for i := range usrs {
@@ -45,6 +147,7 @@ func (cs UserCase) ValidatePassword(id, password string) error {
 	}
 
 	compareErr := helpers.ComparePasswordToHash(usr.Password, password)
+	cs.record(entity.AuditPasswordValidation, id, nil, compareErr == nil)
 	if compareErr != nil {
 		return compareErr
 	}
@@ -52,8 +155,9 @@ func (cs UserCase) ValidatePassword(id, password string) error {
 	return nil
 }
 
-func (cs UserCase) Find(id string) (entity.User, error) {
-	usr, err := cs.service.Find(id)
+// Find reads the user, only the fields given when there are some
+func (cs UserCase) Find(id string, fields ...entity.UserFied) (entity.User, error) {
+	usr, err := cs.service.Find(id, fields...)
 	if err != nil {
 		cs.log.Println(err)
 		return entity.User{}, engine.ErrInternalFailure()
@@ -71,11 +175,23 @@ func (cs UserCase) Create(user entity.User) (entity.User, error) {
 
 	user.Password = passHash
 
-	usr, err := cs.service.Create(user)
+	var usr entity.User
+	err := cs.service.Transaction(func(tx services.UserService) error {
+		var err error
+		usr, err = tx.Create(user)
+		if err != nil {
+			return err
+		}
+		err = tx.AddEvent(entity.NewUserEvent(entity.UserCreated, usr, entity.ChangedFields(entity.User{}, usr)))
+		return cs.undoOnFailure(err, func() error {
+			return tx.Delete(usr.ID)
+		})
+	})
 	if err != nil {
 		cs.log.Println(err)
 		return entity.User{}, engine.ErrInternalFailure()
 	}
+	cs.record(entity.AuditCreate, usr.ID, entity.ChangedFields(entity.User{}, usr), true)
 
 	usr.Password = "" // must never return password to the user
 	return usr, nil
@@ -90,11 +206,28 @@ func (cs UserCase) Update(id string, user entity.User) (entity.User, error) {
 
 		user.Password = passHash
 	}
-	usr, err := cs.service.Update(id, user)
+	before, err := cs.service.Find(id)
+	if err != nil {
+		cs.log.Println(err)
+		return entity.User{}, engine.ErrInternalFailure()
+	}
+	var usr entity.User
+	err = cs.service.Transaction(func(tx services.UserService) error {
+		var err error
+		usr, err = tx.Update(id, user)
+		if err != nil {
+			return err
+		}
+		return cs.undoOnFailure(cs.emitChanges(tx, before, usr), func() error {
+			_, err := tx.Update(id, before)
+			return err
+		})
+	})
 	if err != nil {
 		cs.log.Println(err)
 		return entity.User{}, engine.ErrInternalFailure()
 	}
+	cs.recordChanges(entity.AuditUpdate, before, usr)
 
 	usr.Password = "" // must never return password to the user
 	return usr, nil
@@ -109,21 +242,54 @@ func (cs UserCase) PartialUpdate(id string, user entity.User) (entity.User, erro
 
 		user.Password = passHash
 	}
-	usr, err := cs.service.PartialUpdate(id, user)
+	before, err := cs.service.Find(id)
 	if err != nil {
 		cs.log.Println(err)
 		return entity.User{}, engine.ErrInternalFailure()
 	}
+	var usr entity.User
+	err = cs.service.Transaction(func(tx services.UserService) error {
+		var err error
+		usr, err = tx.PartialUpdate(id, user)
+		if err != nil {
+			return err
+		}
+		return cs.undoOnFailure(cs.emitChanges(tx, before, usr), func() error {
+			_, err := tx.Update(id, before)
+			return err
+		})
+	})
+	if err != nil {
+		cs.log.Println(err)
+		return entity.User{}, engine.ErrInternalFailure()
+	}
+	cs.recordChanges(entity.AuditPartialUpdate, before, usr)
 
 	usr.Password = "" // must never return password to the user
 	return usr, nil
 }
 
 func (cs UserCase) Delete(id string) error {
-	err := cs.service.Delete(id)
+	err := cs.service.Transaction(func(tx services.UserService) error {
+		// a user that can't be read is not restored, deleting it is then a no-op or fails too
+		before, findErr := tx.Find(id)
+		err := tx.Delete(id)
+		if err != nil {
+			return err
+		}
+		err = tx.AddEvent(entity.NewUserEvent(entity.UserDeleted, entity.User{ID: id}, nil))
+		return cs.undoOnFailure(err, func() error {
+			if findErr != nil {
+				return findErr
+			}
+			_, err := tx.Create(before)
+			return err
+		})
+	})
 	if err != nil {
 		cs.log.Println(err)
 		return engine.ErrInternalFailure()
 	}
+	cs.record(entity.AuditDelete, id, nil, true)
 	return nil
 }
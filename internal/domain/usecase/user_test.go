@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/Shodocan/UserService/internal/configs"
@@ -21,6 +22,26 @@ func getConfig(t *testing.T) *configs.EnvVarConfig {
 	}
 	return config
 }
+
+func newAuditMock(ctrl *gomock.Controller) *services.MockAuditService {
+	auditMock := services.NewMockAuditService(ctrl)
+	auditMock.EXPECT().Record(gomock.Any()).Return(entity.AuditEvent{}, nil).AnyTimes()
+	return auditMock
+}
+
+// expectTransactions runs transactions straight on the mock, returning the events added
+func expectTransactions(userServiceMock *services.MockUserService) *[]entity.UserEvent {
+	emitted := &[]entity.UserEvent{}
+	userServiceMock.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx services.UserService) error) error {
+		return fn(userServiceMock)
+	}).AnyTimes()
+	userServiceMock.EXPECT().AddEvent(gomock.Any()).DoAndReturn(func(event entity.UserEvent) error {
+		*emitted = append(*emitted, event)
+		return nil
+	}).AnyTimes()
+	return emitted
+}
+
 func TestSearch(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -30,12 +51,12 @@ func TestSearch(t *testing.T) {
 		{ID: "456", Name: "Samara Casonatto", Email: "wdcasonatto@gmail.com", Password: "509872"},
 	}
 
-	pagination := engine.NewPagination(2, 2, 10)
+	pagination := engine.NewPagination(2, 2, 1, 10)
 
 	userServiceMock := services.NewMockUserService(ctrl)
-	userServiceMock.EXPECT().Query(gomock.Any(), []string{}, 10, 1).Return(users, pagination, nil)
+	userServiceMock.EXPECT().Query(gomock.Any(), []entity.SortRule{}, 10, 1, false).Return(users, pagination, nil)
 
-	retreivedUsers, page, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Search([]entity.UserFilter{}, []string{}, 1, 10)
+	retreivedUsers, page, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Search(entity.UserQuery{}, []entity.SortRule{}, 1, 10, false)
 	assert.Nil(t, err)
 	assert.Equal(t, page, pagination)
 	assert.Equal(t, retreivedUsers, users)
@@ -48,14 +69,53 @@ func TestSearchError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	userServiceMock := services.NewMockUserService(ctrl)
-	userServiceMock.EXPECT().Query(gomock.Any(), []string{}, 10, 1).Return(nil, engine.Pagination{}, fmt.Errorf("adfasdf"))
+	userServiceMock.EXPECT().Query(gomock.Any(), []entity.SortRule{}, 10, 1, false).Return(nil, engine.Pagination{}, fmt.Errorf("adfasdf"))
 
-	retreivedUsers, page, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Search([]entity.UserFilter{}, []string{}, 1, 10)
+	retreivedUsers, page, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Search(entity.UserQuery{}, []entity.SortRule{}, 1, 10, false)
 	assert.NotNil(t, err)
 	assert.Equal(t, page.Total, 0)
 	assert.Len(t, retreivedUsers, 0)
 }
 
+func TestScroll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cursor := entity.Cursor{Values: []interface{}{"Luke"}, ID: "5f1d7f9c7d1e2a0001a1b2c3"}
+	sort := []entity.SortRule{{Field: entity.Name}}
+	users := []entity.User{{ID: "123", Name: "Samara Casonatto", Password: "509872"}}
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	userServiceMock.EXPECT().Scroll(entity.UserQuery{}, sort, cursor, 10, false).Return(users, engine.Pagination{Total: 1}, nil)
+	userServiceMock.EXPECT().Scroll(entity.UserQuery{}, sort, cursor, 5, false).Return(nil, engine.Pagination{}, fmt.Errorf("adfasdf"))
+	useCase := NewUserCase(&configs.EnvVarConfig{}, userServiceMock, newAuditMock(ctrl), configs.NewLog())
+
+	retreivedUsers, page, err := useCase.Scroll(entity.UserQuery{}, sort, cursor, 10, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Empty(t, retreivedUsers[0].Password)
+
+	_, _, err = useCase.Scroll(entity.UserQuery{}, sort, cursor, 5, false)
+	assert.NotNil(t, err)
+}
+
+func TestSearchRegexRequiresPrivilegedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	query := entity.UserQuery{Not: &entity.UserQuery{Filter: &entity.UserFilter{Field: "name", Operator: entity.Regex, Value: "^Sky"}}}
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	useCase := NewUserCase(&configs.EnvVarConfig{}, userServiceMock, newAuditMock(ctrl), configs.NewLog())
+
+	_, _, err := useCase.As(entity.AuditContext{Client: "mobile"}).Search(query, []entity.SortRule{}, 1, 10, false)
+	assert.Equal(t, http.StatusForbidden, err.(*engine.Error).Code)
+	_, _, err = useCase.As(entity.AuditContext{Client: "backoffice"}).Search(query, []entity.SortRule{}, 1, 10, false)
+	assert.Equal(t, http.StatusForbidden, err.(*engine.Error).Code, "the client header is asserted by the caller, it grants nothing")
+
+	userServiceMock.EXPECT().Query(query, []entity.SortRule{}, 10, 1, false).Return([]entity.User{}, engine.Pagination{}, nil)
+	_, _, err = useCase.As(entity.AuditContext{Client: "backoffice", Privileged: true}).Search(query, []entity.SortRule{}, 1, 10, false)
+	assert.Nil(t, err)
+}
+
 func TestFind(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -65,13 +125,70 @@ func TestFind(t *testing.T) {
 	userServiceMock := services.NewMockUserService(ctrl)
 	userServiceMock.EXPECT().Find("123").Return(user, nil)
 
-	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Find("123")
+	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Find("123")
 	assert.Nil(t, err)
 	assert.Equal(t, retreivedUser.ID, "123")
 	assert.Equal(t, retreivedUser.Name, "Walisson Casonatto")
 	assert.Empty(t, retreivedUser.Password)
 }
 
+func TestFindFields(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	userServiceMock.EXPECT().Find("123", entity.ID, entity.Name).Return(entity.User{ID: "123", Name: "Walisson Casonatto"}, nil)
+
+	retreivedUser, err := NewUserCase(&configs.EnvVarConfig{}, userServiceMock, newAuditMock(ctrl), configs.NewLog()).Find("123", entity.ID, entity.Name)
+	assert.Nil(t, err)
+	assert.Equal(t, "Walisson Casonatto", retreivedUser.Name)
+}
+
+func TestFuzzy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	search := entity.FuzzySearch{Text: "skywalkr", Threshold: 0.3, Limit: 10, Candidates: 100}
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	userServiceMock.EXPECT().Fuzzy(search).Return([]entity.User{{ID: "1", Name: "Luke Skywalker", Password: "hash", Score: 0.8}}, nil)
+	useCase := NewUserCase(&configs.EnvVarConfig{}, userServiceMock, newAuditMock(ctrl), configs.NewLog())
+
+	users, err := useCase.Fuzzy(search)
+	assert.Nil(t, err)
+	assert.Empty(t, users[0].Password)
+
+	userServiceMock.EXPECT().Fuzzy(search).Return(nil, fmt.Errorf("adfasdf"))
+	_, err = useCase.Fuzzy(search)
+	assert.NotNil(t, err)
+}
+
+func TestAggregate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	config := &configs.EnvVarConfig{}
+	aggregation := entity.UserAggregation{GroupBy: entity.GroupByEmailDomain}
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	userServiceMock.EXPECT().Aggregate(aggregation).Return([]entity.UserGroup{{Key: "rebels.org", Count: 3}}, nil)
+	useCase := NewUserCase(config, userServiceMock, newAuditMock(ctrl), configs.NewLog())
+
+	groups, err := useCase.Aggregate(aggregation)
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.UserGroup{{Key: "rebels.org", Count: 3}}, groups)
+
+	userServiceMock.EXPECT().Aggregate(aggregation).Return(nil, fmt.Errorf("adfasdf"))
+	_, err = useCase.Aggregate(aggregation)
+	assert.NotNil(t, err)
+
+	aggregation.Query = entity.AllOf(entity.UserFilter{Field: "name", Operator: entity.Regex, Value: "^Sky"})
+	_, err = useCase.As(entity.AuditContext{Client: "mobile"}).Aggregate(aggregation)
+	assert.Equal(t, http.StatusForbidden, err.(*engine.Error).Code)
+
+	userServiceMock.EXPECT().Aggregate(aggregation).Return([]entity.UserGroup{}, nil)
+	_, err = useCase.As(entity.AuditContext{Privileged: true}).Aggregate(aggregation)
+	assert.Nil(t, err)
+}
+
 func TestFindError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -81,7 +198,7 @@ func TestFindError(t *testing.T) {
 	userServiceMock := services.NewMockUserService(ctrl)
 	userServiceMock.EXPECT().Find("123").Return(user, fmt.Errorf("123"))
 
-	_, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Find("123")
+	_, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Find("123")
 	assert.NotNil(t, err)
 }
 
@@ -92,9 +209,10 @@ func TestCreate(t *testing.T) {
 	user := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "32131"}
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
 	userServiceMock.EXPECT().Create(gomock.Any()).Return(user, nil)
 
-	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Create(user)
+	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Create(user)
 	assert.Nil(t, err)
 	assert.Equal(t, retreivedUser.ID, "123")
 	assert.Equal(t, retreivedUser.Name, "Walisson Casonatto")
@@ -108,9 +226,10 @@ func TestCreateError(t *testing.T) {
 	user := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "32131"}
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
 	userServiceMock.EXPECT().Create(gomock.Any()).Return(entity.User{}, fmt.Errorf("123"))
 
-	_, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Create(user)
+	_, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Create(user)
 	assert.NotNil(t, err)
 }
 
@@ -121,9 +240,11 @@ func TestUpdate(t *testing.T) {
 	user := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "32131"}
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(user, nil)
 	userServiceMock.EXPECT().Update("123", gomock.Any()).Return(user, nil)
 
-	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Update("123", user)
+	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Update("123", user)
 	assert.Nil(t, err)
 	assert.Equal(t, retreivedUser.ID, "123")
 	assert.Equal(t, retreivedUser.Name, "Walisson Casonatto")
@@ -137,9 +258,11 @@ func TestUpdateError(t *testing.T) {
 	user := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "32131"}
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(user, nil)
 	userServiceMock.EXPECT().Update("123", gomock.Any()).Return(entity.User{}, fmt.Errorf("123"))
 
-	_, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Update("123", user)
+	_, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Update("123", user)
 	assert.NotNil(t, err)
 }
 
@@ -150,9 +273,11 @@ func TestPartialUpdate(t *testing.T) {
 	user := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "32131"}
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(user, nil)
 	userServiceMock.EXPECT().PartialUpdate("123", gomock.Any()).Return(user, nil)
 
-	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).PartialUpdate("123", user)
+	retreivedUser, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).PartialUpdate("123", user)
 	assert.Nil(t, err)
 	assert.Equal(t, retreivedUser.ID, "123")
 	assert.Equal(t, retreivedUser.Name, "Walisson Casonatto")
@@ -166,9 +291,11 @@ func TestPartialUpdateError(t *testing.T) {
 	user := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "32131"}
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(user, nil)
 	userServiceMock.EXPECT().PartialUpdate("123", gomock.Any()).Return(entity.User{}, fmt.Errorf("123"))
 
-	_, err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).PartialUpdate("123", user)
+	_, err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).PartialUpdate("123", user)
 	assert.NotNil(t, err)
 }
 
@@ -177,9 +304,11 @@ func TestDelete(t *testing.T) {
 	defer ctrl.Finish()
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash"}, nil)
 	userServiceMock.EXPECT().Delete("123").Return(nil)
 
-	err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Delete("123")
+	err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Delete("123")
 	assert.Nil(t, err)
 }
 
@@ -188,9 +317,11 @@ func TestDeleteError(t *testing.T) {
 	defer ctrl.Finish()
 
 	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(entity.User{}, fmt.Errorf("123"))
 	userServiceMock.EXPECT().Delete("123").Return(fmt.Errorf("123"))
 
-	err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).Delete("123")
+	err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).Delete("123")
 	assert.NotNil(t, err)
 }
 
@@ -205,9 +336,131 @@ func TestValidatePassword(t *testing.T) {
 	userServiceMock := services.NewMockUserService(ctrl)
 	userServiceMock.EXPECT().Find("123").Return(user, nil).Times(2)
 
-	err := NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).ValidatePassword("123", "32131")
+	err := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).ValidatePassword("123", "32131")
+	assert.Nil(t, err)
+
+	err = NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog()).ValidatePassword("123", "asdf")
+	assert.NotNil(t, err)
+}
+
+func TestUpdateRecordsAudit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	before := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash1"}
+	after := entity.User{ID: "123", Name: "Walisson De Deus Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash2"}
+	actor := entity.AuditContext{Actor: "admin", Client: "backoffice", IP: "10.0.0.1"}
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(before, nil)
+	userServiceMock.EXPECT().PartialUpdate("123", gomock.Any()).Return(after, nil)
+
+	recorded := []entity.AuditEvent{}
+	auditMock := services.NewMockAuditService(ctrl)
+	auditMock.EXPECT().Record(gomock.Any()).DoAndReturn(func(event entity.AuditEvent) (entity.AuditEvent, error) {
+		recorded = append(recorded, event)
+		return event, nil
+	}).Times(2)
+
+	_, err := NewUserCase(getConfig(t), userServiceMock, auditMock, configs.NewLog()).As(actor).PartialUpdate("123", entity.User{Name: after.Name, Password: "newpass"})
+	assert.Nil(t, err)
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, entity.AuditPartialUpdate, recorded[0].Action)
+	assert.Equal(t, []string{"name", "password"}, recorded[0].Fields)
+	assert.Equal(t, "admin", recorded[0].Actor)
+	assert.Equal(t, "backoffice", recorded[0].Client)
+	assert.Equal(t, "10.0.0.1", recorded[0].IP)
+	assert.Equal(t, entity.AuditPasswordChange, recorded[1].Action)
+	for _, event := range recorded {
+		assert.NotContains(t, helpers.ToJSON(event), "newpass", "must never record password values")
+		assert.NotContains(t, helpers.ToJSON(event), "hash2", "must never record password values")
+	}
+}
+
+func TestAuditFailureDoesNotFailOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Find("123").Return(entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash"}, nil)
+	userServiceMock.EXPECT().Delete("123").Return(nil)
+
+	auditMock := services.NewMockAuditService(ctrl)
+	auditMock.EXPECT().Record(gomock.Any()).Return(entity.AuditEvent{}, fmt.Errorf("audit unavailable"))
+
+	err := NewUserCase(getConfig(t), userServiceMock, auditMock, configs.NewLog()).Delete("123")
 	assert.Nil(t, err)
+}
+
+func TestMutationsEmitEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	before := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash1"}
+	after := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash2"}
+
+	userServiceMock := services.NewMockUserService(ctrl)
+	emitted := expectTransactions(userServiceMock)
+	userServiceMock.EXPECT().Create(gomock.Any()).Return(before, nil)
+	userServiceMock.EXPECT().Find("123").Return(before, nil).Times(2)
+	userServiceMock.EXPECT().PartialUpdate("123", gomock.Any()).Return(after, nil)
+	userServiceMock.EXPECT().Delete("123").Return(nil)
 
-	err = NewUserCase(getConfig(t), userServiceMock, configs.NewLog()).ValidatePassword("123", "asdf")
+	useCase := NewUserCase(getConfig(t), userServiceMock, newAuditMock(ctrl), configs.NewLog())
+	_, err := useCase.Create(entity.User{Name: before.Name, Email: before.Email, Password: "pass"})
+	assert.Nil(t, err)
+	_, err = useCase.PartialUpdate("123", entity.User{Password: "newpass"})
+	assert.Nil(t, err)
+	err = useCase.Delete("123")
+	assert.Nil(t, err)
+
+	types := []entity.UserEventType{}
+	for _, event := range *emitted {
+		types = append(types, event.Type)
+		assert.Equal(t, "123", event.UserID)
+		if event.User != nil {
+			assert.Empty(t, event.User.Password, "events must never carry passwords")
+		}
+	}
+	assert.Equal(t, []entity.UserEventType{entity.UserCreated, entity.UserUpdated, entity.UserPasswordChanged, entity.UserDeleted}, types)
+}
+
+func TestFailedEventUndoesOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	before := entity.User{ID: "123", Name: "Walisson Casonatto", Email: "wdcasonatto@gmail.com", Password: "hash"}
+	after := entity.User{ID: "123", Name: "Han Solo", Email: before.Email, Password: before.Password}
+	userServiceMock := services.NewMockUserService(ctrl)
+	userServiceMock.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(tx services.UserService) error) error {
+		return fn(userServiceMock)
+	}).Times(3)
+	userServiceMock.EXPECT().AddEvent(gomock.Any()).Return(fmt.Errorf("outbox unavailable")).Times(3)
+	gomock.InOrder(
+		userServiceMock.EXPECT().Create(gomock.Any()).Return(before, nil),
+		userServiceMock.EXPECT().Delete("123").Return(nil),
+	)
+	gomock.InOrder(
+		userServiceMock.EXPECT().Find("123").Return(before, nil),
+		userServiceMock.EXPECT().PartialUpdate("123", gomock.Any()).Return(after, nil),
+		userServiceMock.EXPECT().Update("123", before).Return(before, nil),
+	)
+	gomock.InOrder(
+		userServiceMock.EXPECT().Find("123").Return(before, nil),
+		userServiceMock.EXPECT().Delete("123").Return(nil),
+		userServiceMock.EXPECT().Create(before).Return(before, nil),
+	)
+
+	auditMock := services.NewMockAuditService(ctrl)
+	auditMock.EXPECT().Record(gomock.Any()).Times(0)
+
+	useCase := NewUserCase(getConfig(t), userServiceMock, auditMock, configs.NewLog())
+	_, err := useCase.Create(entity.User{Name: before.Name, Email: before.Email, Password: "pass"})
+	assert.NotNil(t, err, "the change must fail when its event can not be stored")
+	_, err = useCase.PartialUpdate("123", entity.User{Name: "Han Solo"})
+	assert.NotNil(t, err)
+	err = useCase.Delete("123")
 	assert.NotNil(t, err)
 }
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/Shodocan/UserService/internal/webhooks"
+)
+
+type WebhookCase struct {
+	config    *configs.EnvVarConfig
+	service   services.WebhookService
+	deliverer *webhooks.Deliverer
+	log       *log.Logger
+}
+
+func NewWebhookCase(config *configs.EnvVarConfig,
+	service services.WebhookService,
+	deliverer *webhooks.Deliverer,
+	log *log.Logger,
+) *WebhookCase {
+	return &WebhookCase{config: config, service: service, deliverer: deliverer, log: log}
+}
+
+// Subscribe registers a subscription, the secret is only returned here
+func (cs WebhookCase) Subscribe(subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	if subscription.Secret == "" {
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			cs.log.Println(err)
+			return entity.WebhookSubscription{}, engine.ErrInternalFailure()
+		}
+		subscription.Secret = secret
+	}
+	if cs.config.WebhooksAllowPrivate != "true" {
+		if err := webhooks.CheckTarget(subscription.URL); err != nil {
+			return entity.WebhookSubscription{}, engine.NewGenericError(http.StatusBadRequest, "Invalid Webhook").
+				ExtraData(map[string]interface{}{"url": err.Error()})
+		}
+	}
+	subscription.ID = ""
+	subscription.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+
+	created, err := cs.service.CreateSubscription(subscription)
+	if err != nil {
+		cs.log.Println(err)
+		return entity.WebhookSubscription{}, engine.ErrInternalFailure()
+	}
+	return created, nil
+}
+
+func (cs WebhookCase) Find(id string) (entity.WebhookSubscription, error) {
+	subscription, err := cs.service.FindSubscription(id)
+	if err == services.ErrSubscriptionNotFound {
+		return entity.WebhookSubscription{}, engine.ErrNotFound().Message("Webhook not found")
+	}
+	if err != nil {
+		cs.log.Println(err)
+		return entity.WebhookSubscription{}, engine.ErrInternalFailure()
+	}
+	subscription.Secret = "" // must never return the secret after creation
+	return subscription, nil
+}
+
+func (cs WebhookCase) List(limit, page int) ([]entity.WebhookSubscription, engine.Pagination, error) {
+	subscriptions, paginate, err := cs.service.ListSubscriptions(page, limit)
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.Pagination{}, engine.ErrInternalFailure()
+	}
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	return subscriptions, paginate, nil
+}
+
+func (cs WebhookCase) Unsubscribe(id string) error {
+	err := cs.service.DeleteSubscription(id)
+	if err == services.ErrSubscriptionNotFound {
+		return engine.ErrNotFound().Message("Webhook not found")
+	}
+	if err != nil {
+		cs.log.Println(err)
+		return engine.ErrInternalFailure()
+	}
+	return nil
+}
+
+func (cs WebhookCase) Deliveries(subscriptionID string, limit, page int) ([]entity.WebhookDelivery, engine.Pagination, error) {
+	deliveries, paginate, err := cs.service.Deliveries(subscriptionID, "", page, limit)
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.Pagination{}, engine.ErrInternalFailure()
+	}
+	return deliveries, paginate, nil
+}
+
+// DeadLetters lists the deliveries of every subscription that exhausted their attempts
+func (cs WebhookCase) DeadLetters(limit, page int) ([]entity.WebhookDelivery, engine.Pagination, error) {
+	deliveries, paginate, err := cs.service.Deliveries("", entity.DeliveryDead, page, limit)
+	if err != nil {
+		cs.log.Println(err)
+		return nil, engine.Pagination{}, engine.ErrInternalFailure()
+	}
+	return deliveries, paginate, nil
+}
+
+// Redeliver sends a delivery again right away, dead deliveries get a fresh set of attempts
+func (cs WebhookCase) Redeliver(subscriptionID, deliveryID string) (entity.WebhookDelivery, error) {
+	delivery, err := cs.service.FindDelivery(deliveryID)
+	if err == services.ErrDeliveryNotFound {
+		return entity.WebhookDelivery{}, engine.ErrNotFound().Message("Delivery not found")
+	}
+	if err != nil {
+		cs.log.Println(err)
+		return entity.WebhookDelivery{}, engine.ErrInternalFailure()
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		return entity.WebhookDelivery{}, engine.ErrNotFound().Message("Delivery not found")
+	}
+
+	if delivery.Status != entity.DeliveryPending {
+		delivery.Attempts = 0
+	}
+	delivery, err = cs.deliverer.Deliver(delivery)
+	if err != nil {
+		cs.log.Println(err)
+		return entity.WebhookDelivery{}, engine.ErrInternalFailure()
+	}
+	return delivery, nil
+}
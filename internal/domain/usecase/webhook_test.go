@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/Shodocan/UserService/internal/webhooks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebhookCase(serviceMock services.WebhookService) *WebhookCase {
+	config := &configs.EnvVarConfig{WebhooksTimeout: "1s", WebhooksRetryBackoff: "1s", WebhooksMaxAttempts: "3"}
+	return NewWebhookCase(config, serviceMock, webhooks.NewDeliverer(config, serviceMock, configs.NewLog()), configs.NewLog())
+}
+
+func TestWebhookSubscribeGeneratesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().CreateSubscription(gomock.Any()).DoAndReturn(func(subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+		assert.NotEmpty(t, subscription.Secret)
+		assert.False(t, subscription.CreatedAt.IsZero())
+		subscription.ID = "sub"
+		return subscription, nil
+	})
+
+	subscription, err := newWebhookCase(serviceMock).Subscribe(entity.WebhookSubscription{URL: "https://example.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "sub", subscription.ID)
+	assert.NotEmpty(t, subscription.Secret, "secret must be returned on creation")
+}
+
+func TestWebhookFindHidesSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{ID: "sub", Secret: "whsec_1"}, nil)
+	serviceMock.EXPECT().ListSubscriptions(1, 10).Return([]entity.WebhookSubscription{{ID: "sub", Secret: "whsec_1"}}, engine.NewPagination(1, 1, 1, 10), nil)
+
+	subscription, err := newWebhookCase(serviceMock).Find("sub")
+	assert.Nil(t, err)
+	assert.Empty(t, subscription.Secret)
+
+	subscriptions, _, err := newWebhookCase(serviceMock).List(10, 1)
+	assert.Nil(t, err)
+	assert.Empty(t, subscriptions[0].Secret)
+}
+
+func TestWebhookRedeliverOtherSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindDelivery("1").Return(entity.WebhookDelivery{ID: "1", SubscriptionID: "other"}, nil)
+
+	_, err := newWebhookCase(serviceMock).Redeliver("sub", "1")
+	assert.NotNil(t, err)
+	assert.Equal(t, 404, err.(*engine.Error).Code)
+}
+
+func TestWebhookDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().Deliveries("", entity.DeliveryDead, 1, 10).Return([]entity.WebhookDelivery{{ID: "1"}}, engine.NewPagination(1, 1, 1, 10), nil)
+
+	deliveries, _, err := newWebhookCase(serviceMock).DeadLetters(10, 1)
+	assert.Nil(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+func TestWebhookSubscribeRefusesPrivateTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, err := newWebhookCase(services.NewMockWebhookService(ctrl)).Subscribe(entity.WebhookSubscription{URL: "http://169.254.169.254/latest"})
+	assert.Equal(t, http.StatusBadRequest, err.(*engine.Error).Code)
+}
+
+func TestWebhookNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("missing").Return(entity.WebhookSubscription{}, services.ErrSubscriptionNotFound)
+	serviceMock.EXPECT().DeleteSubscription("missing").Return(services.ErrSubscriptionNotFound)
+	serviceMock.EXPECT().FindDelivery("missing").Return(entity.WebhookDelivery{}, services.ErrDeliveryNotFound)
+	useCase := newWebhookCase(serviceMock)
+
+	_, err := useCase.Find("missing")
+	assert.Equal(t, http.StatusNotFound, err.(*engine.Error).Code)
+	err = useCase.Unsubscribe("missing")
+	assert.Equal(t, http.StatusNotFound, err.(*engine.Error).Code)
+	_, err = useCase.Redeliver("sub", "missing")
+	assert.Equal(t, http.StatusNotFound, err.(*engine.Error).Code)
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"strings"
+	"sync"
+)
+
+// Broker is the subset of a NATS/Kafka like client the relay needs
+type Broker interface {
+	Publish(subject string, data []byte) error
+	Subscribe(subject string, handler func(data []byte)) (unsubscribe func())
+}
+
+type subscription struct {
+	subject string
+	handler func(data []byte)
+}
+
+// MemoryBroker is an in-process broker, subjects ending with ".>" match every subject with that prefix
+type MemoryBroker struct {
+	mutex  sync.RWMutex
+	nextID int
+	subs   map[int]subscription
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: map[int]subscription{}}
+}
+
+func (b *MemoryBroker) Publish(subject string, data []byte) error {
+	b.mutex.RLock()
+	handlers := []func(data []byte){}
+	for _, sub := range b.subs {
+		if matchSubject(sub.subject, subject) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(data)
+	}
+	return nil
+}
+
+func (b *MemoryBroker) Subscribe(subject string, handler func(data []byte)) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = subscription{subject: subject, handler: handler}
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+func matchSubject(pattern, subject string) bool {
+	if strings.HasSuffix(pattern, ".>") {
+		return strings.HasPrefix(subject, strings.TrimSuffix(pattern, ">"))
+	}
+	return pattern == subject
+}
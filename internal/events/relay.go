@@ -0,0 +1,152 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+)
+
+const maxRetryBackoff = 10 * time.Minute
+
+// Relay publishes the outbox entries to every sink. An entry is only marked as
+// published once all sinks accepted it, so delivery is at least once and
+// consumers must drop duplicates by event ID.
+type Relay struct {
+	outbox      services.OutboxService
+	sinks       []Sink
+	interval    time.Duration
+	backoff     time.Duration
+	maxAttempts int
+	batchSize   int
+	logger      *log.Logger
+	stop        chan struct{}
+	done        sync.WaitGroup
+}
+
+func NewRelay(config *configs.EnvVarConfig, outbox services.OutboxService, logger *log.Logger, sinks ...Sink) *Relay {
+	interval, err := time.ParseDuration(config.EventsRelayInterval)
+	if err != nil {
+		logger.Printf("Invalid events relay interval %s, using 1s", config.EventsRelayInterval)
+		interval = time.Second
+	}
+	backoff, err := time.ParseDuration(config.EventsRetryBackoff)
+	if err != nil {
+		logger.Printf("Invalid events retry backoff %s, using 1s", config.EventsRetryBackoff)
+		backoff = time.Second
+	}
+	maxAttempts, err := strconv.Atoi(config.EventsMaxAttempts)
+	if err != nil || maxAttempts <= 0 {
+		logger.Printf("Invalid events max attempts %s, using 10", config.EventsMaxAttempts)
+		maxAttempts = 10
+	}
+	batchSize, err := strconv.Atoi(config.EventsRelayBatchSize)
+	if err != nil || batchSize <= 0 {
+		logger.Printf("Invalid events relay batch size %s, using 100", config.EventsRelayBatchSize)
+		batchSize = 100
+	}
+	return &Relay{
+		outbox:      outbox,
+		sinks:       sinks,
+		interval:    interval,
+		backoff:     backoff,
+		maxAttempts: maxAttempts,
+		batchSize:   batchSize,
+		logger:      logger,
+	}
+}
+
+// Start polls the outbox in background until Stop is called
+func (r *Relay) Start() {
+	r.stop = make(chan struct{})
+	r.done.Add(1)
+	go func() {
+		defer r.done.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				_, err := r.RunOnce()
+				if err != nil {
+					r.logger.Printf("events relay failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *Relay) Stop() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	r.done.Wait()
+	r.stop = nil
+}
+
+// RunOnce relays one batch of pending entries and returns how many were published
+func (r *Relay) RunOnce() (int, error) {
+	entries, err := r.outbox.Pending(r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, entry := range entries {
+		err := r.publish(entry.Event)
+		if err == nil {
+			published++
+			err = r.outbox.MarkPublished(entry.Event.ID)
+			if err != nil {
+				r.logger.Printf("failed to mark event %s as published: %v", entry.Event.ID, err)
+			}
+			continue
+		}
+
+		attempts := entry.Attempts + 1
+		dead := attempts >= r.maxAttempts
+		if dead {
+			r.logger.Printf("giving up event %s after %d attempts: %v", entry.Event.ID, attempts, err)
+		}
+		err = r.outbox.MarkFailed(entry.Event.ID, attempts, time.Now().UTC().Add(r.retryDelay(attempts)), err.Error(), dead)
+		if err != nil {
+			r.logger.Printf("failed to reschedule event %s: %v", entry.Event.ID, err)
+		}
+	}
+	return published, nil
+}
+
+func (r *Relay) publish(event entity.UserEvent) error {
+	failures := []string{}
+	for _, sink := range r.sinks {
+		err := sink.Publish(event)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", sink.Name(), err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// retryDelay doubles the backoff on every attempt
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	delay := r.backoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
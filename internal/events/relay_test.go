@@ -0,0 +1,137 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func getConfig() *configs.EnvVarConfig {
+	return &configs.EnvVarConfig{
+		EventsRelayInterval:  "10ms",
+		EventsRelayBatchSize: "10",
+		EventsMaxAttempts:    "3",
+		EventsRetryBackoff:   "1s",
+		EventsBroker:         "memory",
+		EventsBrokerSubject:  "events",
+	}
+}
+
+func pendingEntry(id string, attempts int) entity.OutboxEntry {
+	event := entity.NewUserEvent(entity.UserCreated, entity.User{ID: "123", Name: "Walisson Casonatto", Password: "hash"}, []string{"name"})
+	event.ID = id
+	return entity.OutboxEntry{Event: event, Status: entity.OutboxPending, Attempts: attempts}
+}
+
+type failingSink struct{}
+
+func (failingSink) Name() string                         { return "failing" }
+func (failingSink) Publish(event entity.UserEvent) error { return fmt.Errorf("unavailable") }
+
+func TestRelayPublishesToAllSinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	received := make(chan entity.UserEvent, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var event entity.UserEvent
+		assert.Nil(t, json.Unmarshal(body, &event))
+		assert.Equal(t, "1", r.Header.Get("X-Event-Id"))
+		received <- event
+	}))
+	defer receiver.Close()
+
+	config := getConfig()
+	config.EventsWebhookURL = receiver.URL
+	broker := NewMemoryBroker()
+	brokered := [][]byte{}
+	unsubscribe := broker.Subscribe("events.>", func(data []byte) {
+		brokered = append(brokered, data)
+	})
+	defer unsubscribe()
+
+	outboxMock := services.NewMockOutboxService(ctrl)
+	outboxMock.EXPECT().Pending(10).Return([]entity.OutboxEntry{pendingEntry("1", 0)}, nil)
+	outboxMock.EXPECT().MarkPublished("1").Return(nil)
+
+	relay := NewRelay(config, outboxMock, configs.NewLog(), SinksFromConfig(config, broker)...)
+	published, err := relay.RunOnce()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, published)
+	assert.Len(t, brokered, 1)
+
+	event := <-received
+	assert.Equal(t, entity.UserCreated, event.Type)
+	assert.Empty(t, event.User.Password, "events must never carry passwords")
+}
+
+func TestRelayRetriesWithBackoff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outboxMock := services.NewMockOutboxService(ctrl)
+	outboxMock.EXPECT().Pending(10).Return([]entity.OutboxEntry{pendingEntry("1", 0), pendingEntry("2", 2)}, nil)
+	outboxMock.EXPECT().MarkFailed("1", 1, gomock.Any(), gomock.Any(), false).DoAndReturn(
+		func(id string, attempts int, next time.Time, reason string, dead bool) error {
+			assert.WithinDuration(t, time.Now().Add(time.Second), next, 500*time.Millisecond)
+			assert.Contains(t, reason, "failing: unavailable")
+			return nil
+		})
+	outboxMock.EXPECT().MarkFailed("2", 3, gomock.Any(), gomock.Any(), true).Return(nil)
+
+	relay := NewRelay(getConfig(), outboxMock, configs.NewLog(), failingSink{})
+	published, err := relay.RunOnce()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, published)
+}
+
+func TestRelayRetryDelay(t *testing.T) {
+	relay := NewRelay(getConfig(), nil, configs.NewLog())
+	assert.Equal(t, time.Second, relay.retryDelay(1))
+	assert.Equal(t, 2*time.Second, relay.retryDelay(2))
+	assert.Equal(t, 8*time.Second, relay.retryDelay(4))
+	assert.Equal(t, maxRetryBackoff, relay.retryDelay(50))
+}
+
+func TestRelayStartStop(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	outboxMock := services.NewMockOutboxService(ctrl)
+	polled := make(chan struct{}, 10)
+	outboxMock.EXPECT().Pending(10).DoAndReturn(func(limit int) ([]entity.OutboxEntry, error) {
+		polled <- struct{}{}
+		return []entity.OutboxEntry{}, nil
+	}).MinTimes(1)
+
+	relay := NewRelay(getConfig(), outboxMock, configs.NewLog())
+	relay.Start()
+	<-polled
+	relay.Stop()
+}
+
+func TestMemoryBrokerSubjects(t *testing.T) {
+	broker := NewMemoryBroker()
+	exact, wildcard := 0, 0
+	broker.Subscribe(Subject("events", entity.UserDeleted), func(data []byte) { exact++ })
+	unsubscribe := broker.Subscribe("events.>", func(data []byte) { wildcard++ })
+
+	assert.Nil(t, broker.Publish("events.user.deleted", nil))
+	assert.Nil(t, broker.Publish("events.user.created", nil))
+	unsubscribe()
+	assert.Nil(t, broker.Publish("events.user.created", nil))
+
+	assert.Equal(t, 1, exact)
+	assert.Equal(t, 2, wildcard)
+}
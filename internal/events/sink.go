@@ -0,0 +1,99 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// Sink receives the events relayed from the outbox, an error means the event
+// must be delivered again later
+type Sink interface {
+	Name() string
+	Publish(event entity.UserEvent) error
+}
+
+// WebhookSink posts every event as JSON to a fixed URL
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(event entity.UserEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.ID)
+	req.Header.Set("X-Event-Type", string(event.Type))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// BrokerSink publishes every event on a message broker subject named after its type
+type BrokerSink struct {
+	broker  Broker
+	subject string
+}
+
+func NewBrokerSink(broker Broker, subject string) *BrokerSink {
+	return &BrokerSink{broker: broker, subject: subject}
+}
+
+func (s *BrokerSink) Name() string {
+	return "broker"
+}
+
+func (s *BrokerSink) Publish(event entity.UserEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.broker.Publish(Subject(s.subject, event.Type), data)
+}
+
+// Subject returns the subject events of a type are published on
+func Subject(prefix string, eventType entity.UserEventType) string {
+	return fmt.Sprintf("%s.%s", prefix, eventType)
+}
+
+const webhookTimeout = 10 * time.Second
+
+// SinksFromConfig builds the sinks enabled in the configuration, brokers are
+// only available in process for now
+func SinksFromConfig(config *configs.EnvVarConfig, broker Broker) []Sink {
+	sinks := []Sink{}
+	if config.EventsWebhookURL != "" {
+		sinks = append(sinks, NewWebhookSink(config.EventsWebhookURL, webhookTimeout))
+	}
+	if config.EventsBroker == "memory" && broker != nil {
+		sinks = append(sinks, NewBrokerSink(broker, config.EventsBrokerSubject))
+	}
+	return sinks
+}
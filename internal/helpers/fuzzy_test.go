@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigrams(t *testing.T) {
+	assert.Equal(t, []string{"  l", " lu", "luk", "uke", "ke "}, Trigrams("Luke"))
+	assert.Equal(t, []string{"  a", " a ", "  b", " b "}, Trigrams("a@b"), "words are split on punctuation")
+	assert.Empty(t, Trigrams(" .. "))
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, TrigramSimilarity(Trigrams("Skywalker"), Trigrams("skywalker")))
+	assert.Equal(t, 0.0, TrigramSimilarity(Trigrams("Luke"), Trigrams("Han")))
+	assert.InDelta(t, 0.5, TrigramSimilarity(Trigrams("Skywalker"), Trigrams("Skywalkre")), 0.2)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("luke", "luke"))
+	assert.Equal(t, 1, EditDistance("luke", "luk"))
+	assert.Equal(t, 2, EditDistance("skywalker", "skywalkre"))
+	assert.Equal(t, 3, EditDistance("", "han"))
+	assert.Equal(t, 1.0, EditSimilarity("Luke", "luke"))
+	assert.Equal(t, 0.75, EditSimilarity("Luke", "Luka"))
+}
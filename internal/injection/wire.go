@@ -7,10 +7,31 @@ import (
 	"github.com/Shodocan/UserService/internal/database"
 	"github.com/Shodocan/UserService/internal/domain/usecase"
 	"github.com/Shodocan/UserService/internal/services"
+	"github.com/Shodocan/UserService/internal/webhooks"
 	"github.com/google/wire"
 )
 
 func InitializeUserCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.UserCase {
-	wire.Build(usecase.NewUserCase, configs.NewLog, services.NewUserServiceMongo)
+	wire.Build(usecase.NewUserCase, configs.NewLog, services.NewUserServiceMongo, services.NewAuditServiceMongo)
 	return &usecase.UserCase{}
 }
+
+func InitializeAuditCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.AuditCase {
+	wire.Build(usecase.NewAuditCase, configs.NewLog, services.NewAuditServiceMongo)
+	return &usecase.AuditCase{}
+}
+
+func InitializeWebhookCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.WebhookCase {
+	wire.Build(usecase.NewWebhookCase, configs.NewLog, services.NewWebhookServiceMongo, webhooks.NewDeliverer)
+	return &usecase.WebhookCase{}
+}
+
+func InitializeEventCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.EventCase {
+	wire.Build(usecase.NewEventCase, configs.NewLog, services.NewOutboxServiceMongo)
+	return &usecase.EventCase{}
+}
+
+func InitializeCacheCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.CacheCase {
+	wire.Build(usecase.NewCacheCase, configs.NewLog, services.NewCacheService)
+	return &usecase.CacheCase{}
+}
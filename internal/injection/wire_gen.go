@@ -10,13 +10,44 @@ import (
 	"github.com/Shodocan/UserService/internal/database"
 	"github.com/Shodocan/UserService/internal/domain/usecase"
 	"github.com/Shodocan/UserService/internal/services"
+	"github.com/Shodocan/UserService/internal/webhooks"
 )
 
 // Injectors from wire.go:
 
 func InitializeUserCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.UserCase {
 	userService := services.NewUserServiceMongo(db, config)
+	auditService := services.NewAuditServiceMongo(db, config)
 	logger := configs.NewLog()
-	userCase := usecase.NewUserCase(config, userService, logger)
+	userCase := usecase.NewUserCase(config, userService, auditService, logger)
 	return userCase
 }
+
+func InitializeAuditCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.AuditCase {
+	auditService := services.NewAuditServiceMongo(db, config)
+	logger := configs.NewLog()
+	auditCase := usecase.NewAuditCase(config, auditService, logger)
+	return auditCase
+}
+
+func InitializeWebhookCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.WebhookCase {
+	webhookService := services.NewWebhookServiceMongo(db, config)
+	logger := configs.NewLog()
+	deliverer := webhooks.NewDeliverer(config, webhookService, logger)
+	webhookCase := usecase.NewWebhookCase(config, webhookService, deliverer, logger)
+	return webhookCase
+}
+
+func InitializeEventCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.EventCase {
+	outboxService := services.NewOutboxServiceMongo(db, config)
+	logger := configs.NewLog()
+	eventCase := usecase.NewEventCase(config, outboxService, logger)
+	return eventCase
+}
+
+func InitializeCacheCase(config *configs.EnvVarConfig, db database.MongoDB) *usecase.CacheCase {
+	cacheService := services.NewCacheService(db)
+	logger := configs.NewLog()
+	cacheCase := usecase.NewCacheCase(config, cacheService, logger)
+	return cacheCase
+}
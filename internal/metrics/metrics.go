@@ -0,0 +1,230 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Labels identify one series of a metric
+type Labels map[string]string
+
+func (l Labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(l))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, l[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+type Counter struct {
+	value int64
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Add(n int64) {
+	atomic.AddInt64(&c.value, n)
+}
+
+func (c *Counter) Value() int64 {
+	return atomic.LoadInt64(&c.value)
+}
+
+// DefaultBuckets suit latencies in seconds, from a local read to a slow database query
+var DefaultBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Histogram counts observations in buckets by upper bound, exported cumulatively
+type Histogram struct {
+	bounds []float64
+	counts []int64
+	count  int64
+	// sum holds the bits of a float64, updated with compare and swap
+	sum uint64
+}
+
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.bounds, value)
+	if i < len(h.counts) {
+		atomic.AddInt64(&h.counts[i], 1)
+	}
+	atomic.AddInt64(&h.count, 1)
+	for {
+		old := atomic.LoadUint64(&h.sum)
+		sum := math.Float64bits(math.Float64frombits(old) + value)
+		if atomic.CompareAndSwapUint64(&h.sum, old, sum) {
+			return
+		}
+	}
+}
+
+func (h *Histogram) Count() int64 {
+	return atomic.LoadInt64(&h.count)
+}
+
+func (h *Histogram) Sum() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&h.sum))
+}
+
+// series is either a counter, a histogram or a gauge read when exporting
+type series struct {
+	labels    Labels
+	counter   *Counter
+	histogram *Histogram
+	gauge     func() float64
+}
+
+func (s series) value() float64 {
+	if s.counter != nil {
+		return float64(s.counter.Value())
+	}
+	return s.gauge()
+}
+
+func (s series) write(w io.Writer, name string) error {
+	if s.histogram == nil {
+		_, err := fmt.Fprintf(w, "%s%s %s\n", name, s.labels, formatFloat(s.value()))
+		return err
+	}
+	var cumulative int64
+	for i, bound := range s.histogram.bounds {
+		cumulative += atomic.LoadInt64(&s.histogram.counts[i])
+		_, err := fmt.Fprintf(w, "%s_bucket%s %d\n", name, s.labels.with("le", formatFloat(bound)), cumulative)
+		if err != nil {
+			return err
+		}
+	}
+	count := s.histogram.Count()
+	_, err := fmt.Fprintf(w, "%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+		name, s.labels.with("le", "+Inf"), count,
+		name, s.labels, formatFloat(s.histogram.Sum()),
+		name, s.labels, count)
+	return err
+}
+
+// with returns a copy of the labels with one more label
+func (l Labels) with(name, value string) Labels {
+	labels := Labels{name: value}
+	for k, v := range l {
+		labels[k] = v
+	}
+	return labels
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+type family struct {
+	help   string
+	kind   string
+	series map[string]series
+}
+
+// Registry keeps the metrics exported in the Prometheus text format
+type Registry struct {
+	mutex    sync.Mutex
+	families map[string]*family
+}
+
+// Default is the registry exported on /metrics
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// Counter returns the counter of the series, creating it on first use
+func (r *Registry) Counter(name, help string, labels Labels) *Counter {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	f := r.family(name, help, "counter")
+	key := labels.String()
+	if existing, ok := f.series[key]; ok && existing.counter != nil {
+		return existing.counter
+	}
+	counter := &Counter{}
+	f.series[key] = series{labels: labels, counter: counter}
+	return counter
+}
+
+// Histogram returns the histogram of the series, creating it with bounds on first use
+func (r *Registry) Histogram(name, help string, labels Labels, bounds []float64) *Histogram {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	f := r.family(name, help, "histogram")
+	key := labels.String()
+	if existing, ok := f.series[key]; ok && existing.histogram != nil {
+		return existing.histogram
+	}
+	sorted := append([]float64{}, bounds...)
+	sort.Float64s(sorted)
+	histogram := &Histogram{bounds: sorted, counts: make([]int64, len(sorted))}
+	f.series[key] = series{labels: labels, histogram: histogram}
+	return histogram
+}
+
+// GaugeFunc exports the value returned by fn, replacing a previous gauge of the same series
+func (r *Registry) GaugeFunc(name, help string, labels Labels, fn func() float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.family(name, help, "gauge").series[labels.String()] = series{labels: labels, gauge: fn}
+}
+
+func (r *Registry) family(name, help, kind string) *family {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{help: help, kind: kind, series: make(map[string]series)}
+		r.families[name] = f
+	}
+	return f
+}
+
+// Write exports every metric in the Prometheus text format
+func (r *Registry) Write(w io.Writer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := r.families[name]
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, f.help, name, f.kind)
+		if err != nil {
+			return err
+		}
+		keys := make([]string, 0, len(f.series))
+		for key := range f.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			err = f.series[key].write(w, name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
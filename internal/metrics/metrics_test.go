@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("requests_total", "Requests handled", Labels{"op": "find", "cache": "redis"}).Add(2)
+	registry.Counter("requests_total", "Requests handled", Labels{"op": "find", "cache": "redis"}).Inc()
+	registry.Counter("requests_total", "Requests handled", nil).Inc()
+	registry.GaugeFunc("circuit_state", "Circuit state", nil, func() float64 { return 2 })
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, registry.Write(buffer))
+	assert.Equal(t, `# HELP circuit_state Circuit state
+# TYPE circuit_state gauge
+circuit_state 2
+# HELP requests_total Requests handled
+# TYPE requests_total counter
+requests_total 1
+requests_total{cache="redis",op="find"} 3
+`, buffer.String())
+}
+
+func TestHistogramWrite(t *testing.T) {
+	registry := NewRegistry()
+	histogram := registry.Histogram("duration_seconds", "Durations", Labels{"op": "find"}, []float64{1, 0.1})
+	histogram.Observe(0.05)
+	histogram.Observe(0.5)
+	histogram.Observe(3)
+	assert.Same(t, histogram, registry.Histogram("duration_seconds", "Durations", Labels{"op": "find"}, nil))
+	assert.Equal(t, int64(3), histogram.Count())
+	assert.InDelta(t, 3.55, histogram.Sum(), 1e-9)
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, registry.Write(buffer))
+	assert.Equal(t, `# HELP duration_seconds Durations
+# TYPE duration_seconds histogram
+duration_seconds_bucket{le="0.1",op="find"} 1
+duration_seconds_bucket{le="1",op="find"} 2
+duration_seconds_bucket{le="+Inf",op="find"} 3
+duration_seconds_sum{op="find"} 3.55
+duration_seconds_count{op="find"} 3
+`, buffer.String())
+}
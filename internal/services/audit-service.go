@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	auditNamespace    = "audit"
+	auditMaxAttempts  = 5
+	auditSequenceSort = "sequence"
+)
+
+// appends from the same process are serialized, other instances are detected
+// by the unique index on sequence and retried
+var auditMutex sync.Mutex
+
+func NewAuditServiceMongo(db database.MongoDB, config *configs.EnvVarConfig) AuditService {
+	// the chain head must never come from a cache
+	return &AuditServiceMongo{_db: database.SourceOf(db), config: config}
+}
+
+type AuditServiceMongo struct {
+	_db    database.MongoDB
+	config *configs.EnvVarConfig
+}
+
+type DBAuditList []DBAuditEvent
+
+type DBAuditEvent struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Sequence  int64              `bson:"sequence"`
+	UserID    string             `bson:"userId"`
+	Action    string             `bson:"action"`
+	Actor     string             `bson:"actor"`
+	Client    string             `bson:"client"`
+	IP        string             `bson:"ip"`
+	Timestamp time.Time          `bson:"timestamp"`
+	Fields    []string           `bson:"fields"`
+	Success   bool               `bson:"success"`
+	PrevHash  string             `bson:"prevHash"`
+	Hash      string             `bson:"hash"`
+}
+
+func MapDBAuditEvent(event entity.AuditEvent) *DBAuditEvent {
+	return &DBAuditEvent{
+		Sequence:  event.Sequence,
+		UserID:    event.UserID,
+		Action:    string(event.Action),
+		Actor:     event.Actor,
+		Client:    event.Client,
+		IP:        event.IP,
+		Timestamp: event.Timestamp,
+		Fields:    event.Fields,
+		Success:   event.Success,
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+	}
+}
+
+func (dbEvent *DBAuditEvent) ToAuditEvent() entity.AuditEvent {
+	return entity.AuditEvent{
+		ID:        dbEvent.ID.Hex(),
+		Sequence:  dbEvent.Sequence,
+		UserID:    dbEvent.UserID,
+		Action:    entity.AuditAction(dbEvent.Action),
+		Actor:     dbEvent.Actor,
+		Client:    dbEvent.Client,
+		IP:        dbEvent.IP,
+		Timestamp: dbEvent.Timestamp.UTC(),
+		Fields:    dbEvent.Fields,
+		Success:   dbEvent.Success,
+		PrevHash:  dbEvent.PrevHash,
+		Hash:      dbEvent.Hash,
+	}
+}
+
+func (list DBAuditList) ToAuditList() []entity.AuditEvent {
+	result := []entity.AuditEvent{}
+	for _, event := range list {
+		result = append(result, event.ToAuditEvent())
+	}
+	return result
+}
+
+func (repo AuditServiceMongo) Record(event entity.AuditEvent) (entity.AuditEvent, error) {
+	auditMutex.Lock()
+	defer auditMutex.Unlock()
+
+	for attempt := 0; attempt < auditMaxAttempts; attempt++ {
+		head := DBAuditList{}
+		err := repo._db.Query(auditNamespace, bson.M{}, primitive.D{{Key: auditSequenceSort, Value: -1}}, 0, 1, &head)
+		if err != nil {
+			return entity.AuditEvent{}, err
+		}
+
+		event.Sequence = 1
+		event.PrevHash = ""
+		if len(head) > 0 {
+			event.Sequence = head[0].Sequence + 1
+			event.PrevHash = head[0].Hash
+		}
+		event.Hash = event.ComputeHash()
+
+		id, err := repo._db.Create(auditNamespace, MapDBAuditEvent(event))
+		if err == nil {
+			event.ID = id
+			return event, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			return entity.AuditEvent{}, err
+		}
+	}
+	return entity.AuditEvent{}, fmt.Errorf("failed to append audit event after %d attempts", auditMaxAttempts)
+}
+
+func (repo AuditServiceMongo) Query(filter entity.AuditFilter, page, limit int) ([]entity.AuditEvent, engine.Pagination, error) {
+	queryFilter := bson.M{}
+	if filter.UserID != "" {
+		queryFilter["userId"] = filter.UserID
+	}
+	if filter.Actor != "" {
+		queryFilter["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		queryFilter["action"] = string(filter.Action)
+	}
+	period := bson.M{}
+	if filter.From != nil {
+		period["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		period["$lte"] = *filter.To
+	}
+	if len(period) > 0 {
+		queryFilter["timestamp"] = period
+	}
+
+	total, err := repo._db.Total(auditNamespace, queryFilter)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+
+	dbEvents := DBAuditList{}
+	sort := primitive.D{{Key: auditSequenceSort, Value: -1}}
+	err = repo._db.Query(auditNamespace, queryFilter, sort, (page*limit)-limit, limit, &dbEvents)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+
+	return dbEvents.ToAuditList(), engine.NewPagination(total, len(dbEvents), page, limit), nil
+}
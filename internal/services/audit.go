@@ -0,0 +1,12 @@
+package services
+
+import (
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+//go:generate mockgen -destination audit-repository_mock.go -package services . AuditService
+type AuditService interface {
+	Record(event entity.AuditEvent) (entity.AuditEvent, error)
+	Query(filter entity.AuditFilter, page, limit int) ([]entity.AuditEvent, engine.Pagination, error)
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Shodocan/UserService/internal/services (interfaces: CacheService)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	entity "github.com/Shodocan/UserService/internal/domain/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCacheService is a mock of CacheService interface.
+type MockCacheService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheServiceMockRecorder
+}
+
+// MockCacheServiceMockRecorder is the mock recorder for MockCacheService.
+type MockCacheServiceMockRecorder struct {
+	mock *MockCacheService
+}
+
+// NewMockCacheService creates a new mock instance.
+func NewMockCacheService(ctrl *gomock.Controller) *MockCacheService {
+	mock := &MockCacheService{ctrl: ctrl}
+	mock.recorder = &MockCacheServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCacheService) EXPECT() *MockCacheServiceMockRecorder {
+	return m.recorder
+}
+
+// EvictUser mocks base method.
+func (m *MockCacheService) EvictUser(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvictUser indicates an expected call of EvictUser.
+func (mr *MockCacheServiceMockRecorder) EvictUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictUser", reflect.TypeOf((*MockCacheService)(nil).EvictUser), arg0)
+}
+
+// Flush mocks base method.
+func (m *MockCacheService) Flush(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockCacheServiceMockRecorder) Flush(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockCacheService)(nil).Flush), arg0)
+}
+
+// InspectUser mocks base method.
+func (m *MockCacheService) InspectUser(arg0 string) (entity.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InspectUser", arg0)
+	ret0, _ := ret[0].(entity.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InspectUser indicates an expected call of InspectUser.
+func (mr *MockCacheServiceMockRecorder) InspectUser(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InspectUser", reflect.TypeOf((*MockCacheService)(nil).InspectUser), arg0)
+}
+
+// Stats mocks base method.
+func (m *MockCacheService) Stats() (entity.CacheStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats")
+	ret0, _ := ret[0].(entity.CacheStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockCacheServiceMockRecorder) Stats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockCacheService)(nil).Stats))
+}
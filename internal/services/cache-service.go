@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+func NewCacheService(db database.MongoDB) CacheService {
+	admin, _ := db.(database.CacheAdmin)
+	return &CacheServiceDB{admin: admin}
+}
+
+type CacheServiceDB struct {
+	// admin is nil when the database is not cached
+	admin database.CacheAdmin
+}
+
+func (s *CacheServiceDB) Flush(namespace string) (int64, error) {
+	if s.admin == nil {
+		return 0, ErrCacheDisabled
+	}
+	return s.admin.FlushNamespace(namespace)
+}
+
+func (s *CacheServiceDB) Stats() (entity.CacheStats, error) {
+	if s.admin == nil {
+		return entity.CacheStats{}, ErrCacheDisabled
+	}
+	return MapCacheStats(s.admin.CacheStats()), nil
+}
+
+func (s *CacheServiceDB) InspectUser(id string) (entity.CacheEntry, error) {
+	if s.admin == nil {
+		return entity.CacheEntry{}, ErrCacheDisabled
+	}
+	entry, err := s.admin.InspectCache("users", id)
+	if err != nil {
+		return entity.CacheEntry{}, err
+	}
+	mapped := MapCacheEntry(entry)
+	mapped.Data = cachedUser(entry.Data)
+	return mapped, nil
+}
+
+// cachedUser reads the cached document as a user without its password, which must never
+// leave the service. A document that can't be read is left out instead of shown raw
+func cachedUser(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	dbUser := DBUser{}
+	if err := json.Unmarshal(data, &dbUser); err != nil {
+		return nil
+	}
+	sanitized, err := json.Marshal(dbUser.ToUser().Select(entity.UserFields))
+	if err != nil {
+		return nil
+	}
+	return sanitized
+}
+
+func (s *CacheServiceDB) EvictUser(id string) error {
+	if s.admin == nil {
+		return ErrCacheDisabled
+	}
+	return s.admin.EvictCache("users", id)
+}
+
+func MapCacheStats(stats database.CacheStats) entity.CacheStats {
+	mapped := entity.CacheStats{
+		Operations:   make(map[string]entity.CacheOperationStats, len(stats.Operations)),
+		Evictions:    stats.Evictions,
+		LocalEntries: stats.LocalEntries,
+	}
+	for op, operation := range stats.Operations {
+		mappedOp := entity.CacheOperationStats{
+			Hits:             operation.Hits,
+			StaleHits:        operation.StaleHits,
+			Misses:           operation.Misses,
+			Errors:           operation.Errors,
+			AverageLatencyMs: float64(operation.AverageLatency) / float64(time.Millisecond),
+		}
+		reads := operation.Hits + operation.StaleHits + operation.Misses + operation.Errors
+		if reads > 0 {
+			mappedOp.HitRatio = float64(operation.Hits+operation.StaleHits) / float64(reads)
+		}
+		mapped.Operations[op] = mappedOp
+	}
+	return mapped
+}
+
+func MapCacheEntry(entry database.CacheEntry) entity.CacheEntry {
+	mapped := entity.CacheEntry{
+		Key:     entry.Key,
+		Cached:  entry.Cached,
+		Local:   entry.Local,
+		Missing: entry.Missing,
+		Data:    entry.Data,
+	}
+	if entry.Cached {
+		freshUntil := entry.FreshUntil
+		mapped.FreshUntil = &freshUntil
+	}
+	return mapped
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type inspectedCache struct {
+	database.CacheAdmin
+	entry database.CacheEntry
+}
+
+func (c inspectedCache) InspectCache(namespace, idStr string) (database.CacheEntry, error) {
+	return c.entry, nil
+}
+
+func TestInspectUserHidesPassword(t *testing.T) {
+	id := primitive.NewObjectID()
+	data, err := json.Marshal(DBUser{ID: id, Name: "Luke", Email: "luke@rebels.org", Password: "hash"})
+	assert.Nil(t, err)
+	service := &CacheServiceDB{admin: inspectedCache{entry: database.CacheEntry{Key: "users:id:1", Cached: true, Data: data}}}
+
+	entry, err := service.InspectUser(id.Hex())
+	assert.Nil(t, err)
+	inspected := map[string]interface{}{}
+	assert.Nil(t, json.Unmarshal(entry.Data, &inspected))
+	assert.NotContains(t, inspected, "password", "the password must never leave the service")
+	assert.NotContains(t, string(entry.Data), "hash")
+	assert.Equal(t, "Luke", inspected["name"])
+	assert.Equal(t, id.Hex(), inspected["id"])
+
+	service.admin = inspectedCache{entry: database.CacheEntry{Key: "users:id:1", Cached: true, Missing: true}}
+	entry, err = service.InspectUser(id.Hex())
+	assert.Nil(t, err)
+	assert.Nil(t, entry.Data)
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// ErrCacheDisabled is returned when the database has no cache to manage
+var ErrCacheDisabled = errors.New("cache disabled")
+
+//go:generate mockgen -destination cache-repository_mock.go -package services . CacheService
+type CacheService interface {
+	// Flush drops every cached entry of the namespace, returning how many keys were deleted
+	Flush(namespace string) (int64, error)
+	Stats() (entity.CacheStats, error)
+	InspectUser(id string) (entity.CacheEntry, error)
+	EvictUser(id string) error
+}
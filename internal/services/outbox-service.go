@@ -0,0 +1,199 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	outboxNamespace = "outbox"
+	// events are only read from the log once older than this, so transactions
+	// committed slightly out of order are not skipped by readers
+	eventLogSettle = time.Second
+)
+
+func NewOutboxServiceMongo(db database.MongoDB, config *configs.EnvVarConfig) OutboxService {
+	// pending entries must never come from a cache
+	return &OutboxServiceMongo{_db: database.SourceOf(db), config: config}
+}
+
+type OutboxServiceMongo struct {
+	_db    database.MongoDB
+	config *configs.EnvVarConfig
+}
+
+type DBOutboxList []DBOutboxEntry
+
+type DBOutboxEntry struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Type          string             `bson:"type"`
+	UserID        string             `bson:"userId"`
+	User          *DBEventUser       `bson:"user,omitempty"`
+	Fields        []string           `bson:"fields"`
+	OccurredAt    time.Time          `bson:"occurredAt"`
+	Status        string             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	NextAttemptAt time.Time          `bson:"nextAttemptAt"`
+	LastError     string             `bson:"lastError,omitempty"`
+	PublishedAt   *time.Time         `bson:"publishedAt,omitempty"`
+}
+
+// DBEventUser is the user an event carries, without the fields only kept for searches
+type DBEventUser struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	Name    string             `bson:"name"`
+	Age     int                `bson:"age"`
+	Email   string             `bson:"email"`
+	Address string             `bson:"address"`
+}
+
+func MapDBEventUser(user entity.User) *DBEventUser {
+	dbUser := &DBEventUser{}
+	if user.ID != "" {
+		id, _ := primitive.ObjectIDFromHex(user.ID)
+		dbUser.ID = id
+	}
+	dbUser.Name = user.Name
+	dbUser.Age = user.Age
+	dbUser.Email = user.Email
+	dbUser.Address = user.Address
+	return dbUser
+}
+
+func (dbUser *DBEventUser) ToUser() entity.User {
+	return entity.User{
+		ID:      dbUser.ID.Hex(),
+		Name:    dbUser.Name,
+		Age:     dbUser.Age,
+		Email:   dbUser.Email,
+		Address: dbUser.Address,
+	}
+}
+
+func MapDBOutboxEntry(event entity.UserEvent) *DBOutboxEntry {
+	entry := &DBOutboxEntry{
+		Type:          string(event.Type),
+		UserID:        event.UserID,
+		Fields:        event.Fields,
+		OccurredAt:    event.OccurredAt,
+		Status:        string(entity.OutboxPending),
+		NextAttemptAt: event.OccurredAt,
+	}
+	if event.User != nil {
+		entry.User = MapDBEventUser(*event.User)
+	}
+	return entry
+}
+
+func (dbEntry *DBOutboxEntry) ToUserEvent() entity.UserEvent {
+	event := entity.UserEvent{
+		ID:         dbEntry.ID.Hex(),
+		Type:       entity.UserEventType(dbEntry.Type),
+		UserID:     dbEntry.UserID,
+		Fields:     dbEntry.Fields,
+		OccurredAt: dbEntry.OccurredAt.UTC(),
+	}
+	if dbEntry.User != nil {
+		user := dbEntry.User.ToUser()
+		event.User = &user
+	}
+	return event
+}
+
+func (dbEntry *DBOutboxEntry) ToOutboxEntry() entity.OutboxEntry {
+	return entity.OutboxEntry{
+		Event:         dbEntry.ToUserEvent(),
+		Status:        entity.OutboxStatus(dbEntry.Status),
+		Attempts:      dbEntry.Attempts,
+		NextAttemptAt: dbEntry.NextAttemptAt.UTC(),
+		LastError:     dbEntry.LastError,
+		PublishedAt:   dbEntry.PublishedAt,
+	}
+}
+
+func (list DBOutboxList) ToOutboxList() []entity.OutboxEntry {
+	result := []entity.OutboxEntry{}
+	for _, entry := range list {
+		result = append(result, entry.ToOutboxEntry())
+	}
+	return result
+}
+
+func (repo OutboxServiceMongo) Add(event entity.UserEvent) (entity.UserEvent, error) {
+	id, err := repo._db.Create(outboxNamespace, MapDBOutboxEntry(event))
+	if err != nil {
+		return entity.UserEvent{}, err
+	}
+	event.ID = id
+	return event, nil
+}
+
+func (repo OutboxServiceMongo) Pending(limit int) ([]entity.OutboxEntry, error) {
+	filter := bson.M{
+		"status":        string(entity.OutboxPending),
+		"nextAttemptAt": bson.M{"$lte": time.Now().UTC()},
+	}
+	entries := DBOutboxList{}
+	err := repo._db.Query(outboxNamespace, filter, primitive.D{{Key: "_id", Value: 1}}, 0, limit, &entries)
+	if err != nil {
+		return nil, err
+	}
+	return entries.ToOutboxList(), nil
+}
+
+func (repo OutboxServiceMongo) MarkPublished(id string) error {
+	return repo._db.Update(outboxNamespace, id, bson.M{
+		"status":      string(entity.OutboxPublished),
+		"publishedAt": time.Now().UTC(),
+		"lastError":   "",
+	})
+}
+
+func (repo OutboxServiceMongo) MarkFailed(id string, attempts int, nextAttemptAt time.Time, reason string, dead bool) error {
+	status := entity.OutboxPending
+	if dead {
+		status = entity.OutboxDead
+	}
+	return repo._db.Update(outboxNamespace, id, bson.M{
+		"status":        string(status),
+		"attempts":      attempts,
+		"nextAttemptAt": nextAttemptAt,
+		"lastError":     reason,
+	})
+}
+
+func (repo OutboxServiceMongo) Events(afterID string, since time.Time, types []entity.UserEventType, limit int) ([]entity.UserEvent, error) {
+	filter := bson.M{"occurredAt": bson.M{"$lte": time.Now().UTC().Add(-eventLogSettle)}}
+	if afterID != "" {
+		id, err := primitive.ObjectIDFromHex(afterID)
+		if err != nil {
+			return nil, ErrInvalidEventID
+		}
+		filter["_id"] = bson.M{"$gt": id}
+	} else {
+		filter["occurredAt"].(bson.M)["$gt"] = since
+	}
+	if len(types) > 0 {
+		typeNames := []string{}
+		for _, eventType := range types {
+			typeNames = append(typeNames, string(eventType))
+		}
+		filter["type"] = bson.M{"$in": typeNames}
+	}
+
+	entries := DBOutboxList{}
+	err := repo._db.Query(outboxNamespace, filter, primitive.D{{Key: "_id", Value: 1}}, 0, limit, &entries)
+	if err != nil {
+		return nil, err
+	}
+	events := []entity.UserEvent{}
+	for _, entry := range entries {
+		events = append(events, entry.ToUserEvent())
+	}
+	return events, nil
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestOutboxEntryCarriesNoInternalFields(t *testing.T) {
+	user := entity.User{ID: "60b3a8f0c2a4f1a2b3c4d5e6", Name: "Ana Souza", Age: 30, Email: "ana@example.com", Password: "secret", Address: "Rua A, Curitiba"}
+	entry := MapDBOutboxEntry(entity.NewUserEvent(entity.UserCreated, user, nil))
+
+	raw, err := bson.Marshal(entry.User)
+	assert.Nil(t, err)
+	var doc map[string]interface{}
+	assert.Nil(t, bson.Unmarshal(raw, &doc))
+	for _, field := range []string{"grams", "score", "password"} {
+		assert.NotContains(t, doc, field)
+	}
+
+	event := entry.ToUserEvent()
+	user.Password = ""
+	assert.Equal(t, &user, event.User)
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// ErrInvalidEventID is returned when reading the event log after an ID it never issued
+var ErrInvalidEventID = errors.New("invalid event id")
+
+//go:generate mockgen -destination outbox-repository_mock.go -package services . OutboxService
+type OutboxService interface {
+	Add(event entity.UserEvent) (entity.UserEvent, error)
+	Pending(limit int) ([]entity.OutboxEntry, error)
+	MarkPublished(id string) error
+	MarkFailed(id string, attempts int, nextAttemptAt time.Time, reason string, dead bool) error
+	// Events reads the outbox as an event log, after the event afterID or,
+	// without it, the events that occurred after since
+	Events(afterID string, since time.Time, types []entity.UserEventType, limit int) ([]entity.UserEvent, error)
+}
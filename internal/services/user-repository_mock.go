@@ -35,6 +35,35 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 	return m.recorder
 }
 
+// AddEvent mocks base method.
+func (m *MockUserService) AddEvent(arg0 entity.UserEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddEvent", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddEvent indicates an expected call of AddEvent.
+func (mr *MockUserServiceMockRecorder) AddEvent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddEvent", reflect.TypeOf((*MockUserService)(nil).AddEvent), arg0)
+}
+
+// Aggregate mocks base method.
+func (m *MockUserService) Aggregate(arg0 entity.UserAggregation) ([]entity.UserGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Aggregate", arg0)
+	ret0, _ := ret[0].([]entity.UserGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Aggregate indicates an expected call of Aggregate.
+func (mr *MockUserServiceMockRecorder) Aggregate(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Aggregate", reflect.TypeOf((*MockUserService)(nil).Aggregate), arg0)
+}
+
 // Create mocks base method.
 func (m *MockUserService) Create(arg0 entity.User) (entity.User, error) {
 	m.ctrl.T.Helper()
@@ -65,18 +94,38 @@ func (mr *MockUserServiceMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 }
 
 // Find mocks base method.
-func (m *MockUserService) Find(arg0 string) (entity.User, error) {
+func (m *MockUserService) Find(arg0 string, arg1 ...entity.UserFied) (entity.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Find", arg0)
+	varargs := []interface{}{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Find", varargs...)
 	ret0, _ := ret[0].(entity.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Find indicates an expected call of Find.
-func (mr *MockUserServiceMockRecorder) Find(arg0 interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Find(arg0 interface{}, arg1 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUserService)(nil).Find), arg0)
+	varargs := append([]interface{}{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockUserService)(nil).Find), varargs...)
+}
+
+// Fuzzy mocks base method.
+func (m *MockUserService) Fuzzy(arg0 entity.FuzzySearch) ([]entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fuzzy", arg0)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Fuzzy indicates an expected call of Fuzzy.
+func (mr *MockUserServiceMockRecorder) Fuzzy(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fuzzy", reflect.TypeOf((*MockUserService)(nil).Fuzzy), arg0)
 }
 
 // PartialUpdate mocks base method.
@@ -95,9 +144,13 @@ func (mr *MockUserServiceMockRecorder) PartialUpdate(arg0, arg1 interface{}) *go
 }
 
 // Query mocks base method.
-func (m *MockUserService) Query(arg0 []entity.UserFilter, arg1 []string, arg2, arg3 int) ([]entity.User, engine.Pagination, error) {
+func (m *MockUserService) Query(arg0 entity.UserQuery, arg1 []entity.SortRule, arg2, arg3 int, arg4 bool, arg5 ...entity.UserFied) ([]entity.User, engine.Pagination, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Query", arg0, arg1, arg2, arg3)
+	varargs := []interface{}{arg0, arg1, arg2, arg3, arg4}
+	for _, a := range arg5 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Query", varargs...)
 	ret0, _ := ret[0].([]entity.User)
 	ret1, _ := ret[1].(engine.Pagination)
 	ret2, _ := ret[2].(error)
@@ -105,9 +158,45 @@ func (m *MockUserService) Query(arg0 []entity.UserFilter, arg1 []string, arg2, a
 }
 
 // Query indicates an expected call of Query.
-func (mr *MockUserServiceMockRecorder) Query(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Query(arg0, arg1, arg2, arg3, arg4 interface{}, arg5 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3, arg4}, arg5...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockUserService)(nil).Query), varargs...)
+}
+
+// Scroll mocks base method.
+func (m *MockUserService) Scroll(arg0 entity.UserQuery, arg1 []entity.SortRule, arg2 entity.Cursor, arg3 int, arg4 bool, arg5 ...entity.UserFied) ([]entity.User, engine.Pagination, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1, arg2, arg3, arg4}
+	for _, a := range arg5 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Scroll", varargs...)
+	ret0, _ := ret[0].([]entity.User)
+	ret1, _ := ret[1].(engine.Pagination)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Scroll indicates an expected call of Scroll.
+func (mr *MockUserServiceMockRecorder) Scroll(arg0, arg1, arg2, arg3, arg4 interface{}, arg5 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1, arg2, arg3, arg4}, arg5...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Scroll", reflect.TypeOf((*MockUserService)(nil).Scroll), varargs...)
+}
+
+// Transaction mocks base method.
+func (m *MockUserService) Transaction(arg0 func(UserService) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockUserServiceMockRecorder) Transaction(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockUserService)(nil).Query), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockUserService)(nil).Transaction), arg0)
 }
 
 // Update mocks base method.
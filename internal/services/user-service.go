@@ -1,12 +1,17 @@
 package services
 
 import (
-	"strings"
+	"errors"
+	"math"
+	"regexp"
+	"sort"
+	"time"
 
 	"github.com/Shodocan/UserService/internal/configs"
 	"github.com/Shodocan/UserService/internal/configs/engine"
 	"github.com/Shodocan/UserService/internal/database"
 	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/helpers"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -31,9 +36,15 @@ func MapDBUser(user entity.User) *DBUser {
 	dbUser.Age = user.Age
 	dbUser.Name = user.Name
 	dbUser.Email = user.Email
+	dbUser.Grams = userGrams(user.Name, user.Email)
 	return dbUser
 }
 
+// userGrams are the trigrams of the name and email a fuzzy search finds the user by
+func userGrams(name, email string) []string {
+	return helpers.Trigrams(name + " " + email)
+}
+
 func MapPartialUser(user entity.User) *DBUserPartial {
 	dbUser := &DBUserPartial{}
 	if user.ID != "" {
@@ -55,6 +66,7 @@ type DBUserPartial struct {
 	Email    string             `json:"email" bson:"email,omitempty"`
 	Password string             `json:"password" bson:"password,omitempty"`
 	Address  string             `json:"address" bson:"address,omitempty"`
+	Grams    []string           `json:"-" bson:"grams,omitempty"`
 }
 
 type DBUserList []DBUser
@@ -66,6 +78,10 @@ type DBUser struct {
 	Email    string             `json:"email" bson:"email"`
 	Password string             `json:"password" bson:"password,omitempty"`
 	Address  string             `json:"address" bson:"address"`
+	// Grams are only read by fuzzy searches, they aren't cached
+	Grams []string `json:"-" bson:"grams"`
+	// Score is only read by text searches, it is never written
+	Score float64 `json:"score,omitempty" bson:"score,omitempty"`
 }
 
 func (dbUser *DBUser) ToUser() entity.User {
@@ -76,6 +92,7 @@ func (dbUser *DBUser) ToUser() entity.User {
 		Email:    dbUser.Email,
 		Password: dbUser.Password,
 		Address:  dbUser.Address,
+		Score:    dbUser.Score,
 	}
 }
 
@@ -91,39 +108,320 @@ func (repo UserServiceMongo) operationResolver(op entity.FilterOperation) string
 	switch op {
 	case entity.Equal:
 		return "$eq"
+	case entity.NotEqual:
+		return "$ne"
+	case entity.Greater:
+		return "$gt"
+	case entity.GreaterOrEqual:
+		return "$gte"
+	case entity.Less:
+		return "$lt"
+	case entity.LessOrEqual:
+		return "$lte"
+	case entity.In:
+		return "$in"
+	case entity.NotIn:
+		return "$nin"
+	case entity.Exists:
+		return "$exists"
 	default:
 		return "$regex"
 	}
 }
 
-func (repo UserServiceMongo) Query(filters []entity.UserFilter, sortList []string, page, limit int) ([]entity.User, engine.Pagination, error) {
-	queryFilter := bson.M{}
-	for _, filter := range filters {
-		if filter.Valid() {
-			queryFilter[string(filter.Field)] = bson.M{repo.operationResolver(filter.Operator): filter.Value}
+// queryFilter translates the expression to a mongo filter, not is a $nor of
+// its node as mongo's $not only applies to a single field
+func (repo UserServiceMongo) queryFilter(query entity.UserQuery) (bson.M, error) {
+	query, err := query.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	filter := repo.expression(query)
+	if query.Text != "" {
+		// the expression has a single key, $text never collides with it
+		filter["$text"] = bson.M{"$search": query.Text}
+	}
+	return filter, nil
+}
+
+// searchesText reports whether the filter, or a filter it is a conjunction of, is a text search
+func searchesText(filter bson.M) bool {
+	if _, ok := filter["$text"]; ok {
+		return true
+	}
+	and, _ := filter["$and"].([]bson.M)
+	for _, child := range and {
+		if searchesText(child) {
+			return true
 		}
 	}
+	return false
+}
+
+func (repo UserServiceMongo) expression(query entity.UserQuery) bson.M {
+	switch {
+	case query.Filter != nil:
+		filter := *query.Filter
+		if filter.Operator.Pattern() {
+			return bson.M{string(filter.Field): repo.pattern(filter)}
+		}
+		return bson.M{string(filter.Field): bson.M{repo.operationResolver(filter.Operator): filter.Value}}
+	case query.Not != nil:
+		return bson.M{"$nor": []bson.M{repo.expression(*query.Not)}}
+	case len(query.And) == 1:
+		return repo.expression(query.And[0])
+	case len(query.And) > 0:
+		return bson.M{"$and": repo.expressions(query.And)}
+	case len(query.Or) > 0:
+		return bson.M{"$or": repo.expressions(query.Or)}
+	default:
+		return bson.M{}
+	}
+}
+
+// pattern translates the pattern operations to a $regex, only regex is not escaped
+func (repo UserServiceMongo) pattern(filter entity.UserFilter) bson.M {
+	value := filter.Value.(string)
+	if filter.Operator != entity.Regex {
+		value = regexp.QuoteMeta(value)
+	}
+	switch filter.Operator {
+	case entity.Prefix, entity.StartsWith:
+		value = "^" + value
+	case entity.EndsWith:
+		value += "$"
+	case entity.IEquals:
+		value = "^" + value + "$"
+	}
+	condition := bson.M{"$regex": value}
+	if filter.IgnoreCase || filter.Operator == entity.IEquals {
+		condition["$options"] = "i"
+	}
+	return condition
+}
 
+func (repo UserServiceMongo) expressions(queries []entity.UserQuery) []bson.M {
+	expressions := make([]bson.M, 0, len(queries))
+	for _, query := range queries {
+		expressions = append(expressions, repo.expression(query))
+	}
+	return expressions
+}
+
+// regexMaxTime bounds how long the server runs a query matching a raw regex, the
+// validation can't rule out every slow pattern
+const regexMaxTime = 2 * time.Second
+
+// limited bounds the time the filter or pipeline of query may run when it matches a raw regex
+func limited(query entity.UserQuery, filterOrPipeline interface{}) interface{} {
+	if !query.Uses(entity.Regex) {
+		return filterOrPipeline
+	}
+	return database.TimeLimited{Query: filterOrPipeline, MaxTime: regexMaxTime}
+}
+
+// userCollation compares names and emails ignoring their case, like the collated indexes
+// serving their sorts. Equality, ranges and sorts all compare the same way
+var userCollation = database.Collation{Locale: "en", Strength: 2}
+
+// collate runs every comparison of the filter with the user collation, so the users matched and
+// their order don't depend on the sort, except for text searches as text indexes don't support collations
+func collate(filter bson.M) interface{} {
+	if searchesText(filter) {
+		return filter
+	}
+	return database.CollatedFilter{Filter: filter, Collation: userCollation}
+}
+
+// sort builds the mongo sort, _id breaks ties so pages are stable
+func (repo UserServiceMongo) sort(rules []entity.SortRule) primitive.D {
 	sort := primitive.D{}
-	for _, rule := range sortList {
-		if strings.Contains(rule, "-") {
-			sort = append(sort, primitive.E{Key: strings.ReplaceAll(rule, "-", ""), Value: -1})
-		} else {
-			sort = append(sort, primitive.E{Key: rule, Value: 1})
+	for _, rule := range rules {
+		if rule.Field == entity.Score {
+			sort = append(sort, primitive.E{Key: database.TextScore, Value: bson.M{"$meta": "textScore"}})
+			continue
 		}
+		direction := 1
+		if rule.Descending {
+			direction = -1
+		}
+		sort = append(sort, primitive.E{Key: string(rule.Field), Value: direction})
 	}
+	return append(sort, primitive.E{Key: string(entity.ID), Value: 1})
+}
 
-	total, err := repo._db.Total("users", queryFilter)
+// keyset matches the users after the cursor in the sort order, or before it for backward cursors.
+// For the sort a,b it is a>va or (a=va and b>vb) or (a=va and b=vb and _id>id)
+func (repo UserServiceMongo) keyset(rules []entity.SortRule, cursor entity.Cursor) (bson.M, error) {
+	id, err := primitive.ObjectIDFromHex(cursor.ID)
+	if err != nil {
+		return nil, err
+	}
+	compare := func(descending bool) string {
+		if descending != cursor.Backward {
+			return "$lt"
+		}
+		return "$gt"
+	}
+
+	or := []bson.M{}
+	equal := bson.M{}
+	for i, rule := range rules {
+		after := bson.M{string(rule.Field): bson.M{compare(rule.Descending): cursor.Values[i]}}
+		for field, value := range equal {
+			after[field] = value
+		}
+		or = append(or, after)
+		equal[string(rule.Field)] = cursor.Values[i]
+	}
+	equal[string(entity.ID)] = bson.M{compare(false): id}
+	or = append(or, equal)
+	return bson.M{"$or": or}, nil
+}
+
+// reversed flips every direction of the sort, backward cursors read the page in reverse
+func reversed(sort primitive.D) primitive.D {
+	flipped := make(primitive.D, 0, len(sort))
+	for _, rule := range sort {
+		flipped = append(flipped, primitive.E{Key: rule.Key, Value: -rule.Value.(int)})
+	}
+	return flipped
+}
+
+// projection lists the stored fields read for the fields of the users, every field but the
+// password when there is none. The sort fields are always read, the cursors are made of them,
+// and so is the score of text searches
+func projection(query entity.UserQuery, fields []entity.UserFied, sortList []entity.SortRule) []string {
+	if len(fields) == 0 {
+		fields = entity.UserFields
+	}
+	read := []string{}
+	seen := map[entity.UserFied]bool{}
+	include := func(field entity.UserFied) {
+		if !seen[field] {
+			seen[field] = true
+			read = append(read, string(field))
+		}
+	}
+	// the id is always read, it keeps the projection from being empty for fields=id
+	include(entity.ID)
+	for _, field := range fields {
+		include(field)
+	}
+	for _, rule := range sortList {
+		include(rule.Field)
+	}
+	if query.Text != "" {
+		include(entity.Score)
+	}
+	return read
+}
+
+// count is the pagination of a page of the users matching filter, skipTotal leaves the users uncounted
+func (repo UserServiceMongo) count(filter interface{}, returned, page, limit int, skipTotal, more bool) (engine.Pagination, error) {
+	if skipTotal {
+		return engine.NewUncountedPagination(returned, page, limit, more), nil
+	}
+	total, err := repo._db.Total("users", filter)
+	if err != nil {
+		return engine.Pagination{}, err
+	}
+	return engine.NewPagination(total, returned, page, limit), nil
+}
+
+func (repo UserServiceMongo) Query(query entity.UserQuery, sortList []entity.SortRule, page, limit int, skipTotal bool, fields ...entity.UserFied) ([]entity.User, engine.Pagination, error) {
+	queryFilter, err := repo.queryFilter(query)
 	if err != nil {
 		return nil, engine.Pagination{}, err
 	}
 
+	if query.Text != "" && len(sortList) == 0 {
+		sortList = []entity.SortRule{{Field: entity.Score}}
+	}
+	sort := repo.sort(sortList)
+	filter := limited(query, collate(queryFilter))
+
+	// one more user tells whether there is a page beyond this one without counting them
 	dbUsers := DBUserList{}
-	err = repo._db.Query("users", queryFilter, sort, (page*limit)-limit, limit, &dbUsers)
+	err = repo._db.Query("users", filter, sort, (page*limit)-limit, limit+1, &dbUsers, projection(query, fields, sortList)...)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	more := len(dbUsers) > limit
+	if more {
+		dbUsers = dbUsers[:limit]
+	}
+	users := dbUsers.ToUserList()
+
+	pagination, err := repo.count(filter, len(users), page, limit, skipTotal, more)
 	if err != nil {
 		return nil, engine.Pagination{}, err
 	}
-	return dbUsers.ToUserList(), engine.NewPagination(total, len(dbUsers), limit), err
+	// the cursors let the clients switch to keyset pagination from any page, a score
+	// can't be compared in a filter so relevance sorts are only paginated by page number
+	if len(users) == 0 || entity.SortsByScore(sortList) {
+		return users, pagination, nil
+	}
+	if more {
+		pagination.Next = entity.NewCursor(users[len(users)-1], sortList, false).Encode()
+	}
+	if page > 1 {
+		pagination.Prev = entity.NewCursor(users[0], sortList, true).Encode()
+	}
+	return users, pagination, nil
+}
+
+// Scroll lists the users next to the cursor, it stays consistent while users are added
+// and its cost doesn't grow with the position like skipping pages does
+func (repo UserServiceMongo) Scroll(query entity.UserQuery, sortList []entity.SortRule, cursor entity.Cursor, limit int, skipTotal bool, fields ...entity.UserFied) ([]entity.User, engine.Pagination, error) {
+	if entity.SortsByScore(sortList) {
+		return nil, engine.Pagination{}, errors.New("a sort by score can't be paginated by cursor")
+	}
+	queryFilter, err := repo.queryFilter(query)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	keyset, err := repo.keyset(sortList, cursor)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+
+	sort := repo.sort(sortList)
+	filter := limited(query, collate(bson.M{"$and": []bson.M{queryFilter, keyset}}))
+	if cursor.Backward {
+		sort = reversed(sort)
+	}
+	dbUsers := DBUserList{}
+	err = repo._db.Query("users", filter, sort, 0, limit+1, &dbUsers, projection(query, fields, sortList)...)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	more := len(dbUsers) > limit
+	if more {
+		dbUsers = dbUsers[:limit]
+	}
+	users := dbUsers.ToUserList()
+	if cursor.Backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+
+	// the whole listing is counted, not only the users after the cursor
+	pagination, err := repo.count(limited(query, collate(queryFilter)), len(users), 0, limit, skipTotal, false)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	// the page a cursor was followed from is always there
+	if len(users) > 0 && (more || cursor.Backward) {
+		pagination.Next = entity.NewCursor(users[len(users)-1], sortList, false).Encode()
+	}
+	if len(users) > 0 && (more || !cursor.Backward) {
+		pagination.Prev = entity.NewCursor(users[0], sortList, true).Encode()
+	}
+	pagination.HasNext = pagination.Next != ""
+	pagination.HasPrev = pagination.Prev != ""
+	return users, pagination, nil
 }
 
 func (repo UserServiceMongo) Create(data entity.User) (entity.User, error) {
@@ -135,9 +433,14 @@ func (repo UserServiceMongo) Create(data entity.User) (entity.User, error) {
 	return repo.Find(id)
 }
 
-func (repo UserServiceMongo) Find(id string) (entity.User, error) {
+// Find reads the user, only the fields listed when there are some
+func (repo UserServiceMongo) Find(id string, fields ...entity.UserFied) (entity.User, error) {
 	mongoUser := &DBUser{}
-	err := repo._db.Find("users", id, mongoUser)
+	read := []string{}
+	if len(fields) > 0 {
+		read = projection(entity.UserQuery{}, fields, nil)
+	}
+	err := repo._db.Find("users", id, mongoUser, read...)
 	return mongoUser.ToUser(), err
 }
 
@@ -152,6 +455,24 @@ func (repo UserServiceMongo) Update(id string, user entity.User) (entity.User, e
 
 func (repo UserServiceMongo) PartialUpdate(id string, user entity.User) (entity.User, error) {
 	mongoUser := MapPartialUser(user)
+	if user.Name != "" || user.Email != "" {
+		// the grams are of the name and the email, the one not updated is read
+		current := user
+		if user.Name == "" || user.Email == "" {
+			var err error
+			current, err = repo.Find(id)
+			if err != nil {
+				return entity.User{}, err
+			}
+			if user.Name != "" {
+				current.Name = user.Name
+			}
+			if user.Email != "" {
+				current.Email = user.Email
+			}
+		}
+		mongoUser.Grams = userGrams(current.Name, current.Email)
+	}
 	err := repo._db.Update("users", id, mongoUser)
 	if err != nil {
 		return entity.User{}, err
@@ -159,6 +480,191 @@ func (repo UserServiceMongo) PartialUpdate(id string, user entity.User) (entity.
 	return repo.Find(id)
 }
 
+// candidatePipeline reads the users sharing the most trigrams with grams. Common grams, like the
+// start of a word or a domain, are shared by nearly every user, so the users are ranked by
+// the grams they share before being limited, the best matches are never left out
+func candidatePipeline(grams []string, limit int) []interface{} {
+	fields := bson.M{"shared": bson.M{"$size": bson.M{"$setIntersection": []interface{}{"$grams", grams}}}}
+	for _, field := range projection(entity.UserQuery{}, nil, nil) {
+		fields[field] = 1
+	}
+	return []interface{}{
+		bson.M{"$match": bson.M{"grams": bson.M{"$in": grams}}},
+		bson.M{"$project": fields},
+		bson.M{"$sort": primitive.D{{Key: "shared", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	}
+}
+
+// Fuzzy ranks the users by the similarity of their name or email to the text, the best of
+// the trigram similarity and the edit similarity, and drops those below the threshold
+func (repo UserServiceMongo) Fuzzy(search entity.FuzzySearch) ([]entity.User, error) {
+	grams := helpers.Trigrams(search.Text)
+	if len(grams) == 0 {
+		return []entity.User{}, nil
+	}
+	candidates := DBUserList{}
+	err := repo._db.Aggregate("users", candidatePipeline(grams, search.Candidates), &candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []entity.User{}
+	for _, candidate := range candidates.ToUserList() {
+		candidate.Score = 0
+		for _, field := range []string{candidate.Name, candidate.Email} {
+			score := helpers.TrigramSimilarity(grams, helpers.Trigrams(field))
+			if edit := helpers.EditSimilarity(search.Text, field); edit > score {
+				score = edit
+			}
+			if score > candidate.Score {
+				candidate.Score = score
+			}
+		}
+		if candidate.Score >= search.Threshold {
+			users = append(users, candidate)
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].Score != users[j].Score {
+			return users[i].Score > users[j].Score
+		}
+		return users[i].Name < users[j].Name
+	})
+	if len(users) > search.Limit {
+		users = users[:search.Limit]
+	}
+	return users, nil
+}
+
+// groupKeys are the expressions computing the key of each user for the groupings
+// other than age, which is bucketed. The creation time is the one of the object id
+var groupKeys = map[entity.UserGrouping]interface{}{
+	entity.GroupByEmailDomain:  bson.M{"$toLower": bson.M{"$arrayElemAt": []interface{}{bson.M{"$split": []interface{}{"$email", "@"}}, 1}}},
+	entity.GroupByCity:         bson.M{"$trim": bson.M{"input": bson.M{"$arrayElemAt": []interface{}{bson.M{"$split": []interface{}{"$address", ","}}, -1}}}},
+	entity.GroupByCreatedDay:   bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": bson.M{"$toDate": "$_id"}}},
+	entity.GroupByCreatedMonth: bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": bson.M{"$toDate": "$_id"}}},
+}
+
+// DBGroup is a group output by an aggregation, its key is the lower bound of a bucket when bucketed
+type DBGroup struct {
+	Key   interface{} `bson:"_id"`
+	Count int         `bson:"count"`
+}
+
+// pipeline counts the users matching the filter by the grouping. Dates are kept
+// in order, the most recent ones when too many, other groups the largest first
+func pipeline(filter bson.M, aggregation entity.UserAggregation) []interface{} {
+	stages := []interface{}{bson.M{"$match": filter}}
+	if aggregation.GroupBy == entity.GroupByAge {
+		boundaries := make([]interface{}, 0, len(aggregation.Buckets)+1)
+		for _, bucket := range aggregation.Buckets {
+			boundaries = append(boundaries, bucket)
+		}
+		// the last bracket has no upper bound
+		boundaries = append(boundaries, math.MaxInt32)
+		return append(stages, bson.M{"$bucket": bson.M{
+			"groupBy":    "$age",
+			"boundaries": boundaries,
+			"default":    entity.UnknownGroup,
+			"output":     bson.M{"count": bson.M{"$sum": 1}},
+		}})
+	}
+
+	stages = append(stages, bson.M{"$group": bson.M{"_id": groupKeys[aggregation.GroupBy], "count": bson.M{"$sum": 1}}})
+	if aggregation.GroupBy == entity.GroupByCreatedDay || aggregation.GroupBy == entity.GroupByCreatedMonth {
+		return append(stages,
+			bson.M{"$sort": primitive.D{{Key: "_id", Value: -1}}},
+			bson.M{"$limit": entity.MaxUserGroups},
+			bson.M{"$sort": primitive.D{{Key: "_id", Value: 1}}},
+		)
+	}
+	return append(stages,
+		bson.M{"$sort": primitive.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": entity.MaxUserGroups},
+	)
+}
+
+// Aggregate counts the users by the grouping, every age bracket is listed even when empty
+// and the users without a key are counted in the unknown group, listed last
+func (repo UserServiceMongo) Aggregate(aggregation entity.UserAggregation) ([]entity.UserGroup, error) {
+	if aggregation.GroupBy == entity.GroupByAge && len(aggregation.Buckets) == 0 {
+		aggregation.Buckets = entity.DefaultAgeBuckets
+	}
+	filter, err := repo.queryFilter(aggregation.Query)
+	if err != nil {
+		return nil, err
+	}
+	// the users are matched like the listings match them
+	var stages interface{} = pipeline(filter, aggregation)
+	if !searchesText(filter) {
+		stages = database.CollatedFilter{Filter: stages, Collation: userCollation}
+	}
+	dbGroups := []DBGroup{}
+	err = repo._db.Aggregate("users", limited(aggregation.Query, stages), &dbGroups)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []entity.UserGroup{}
+	if aggregation.GroupBy == entity.GroupByAge {
+		for i := range aggregation.Buckets {
+			groups = append(groups, entity.UserGroup{Key: entity.AgeBracket(aggregation.Buckets, i)})
+		}
+	}
+	unknown := 0
+	for _, dbGroup := range dbGroups {
+		if aggregation.GroupBy == entity.GroupByAge {
+			if i, ok := bucketIndex(aggregation.Buckets, dbGroup.Key); ok {
+				groups[i].Count = dbGroup.Count
+				continue
+			}
+		} else if key, ok := dbGroup.Key.(string); ok && key != "" {
+			groups = append(groups, entity.UserGroup{Key: key, Count: dbGroup.Count})
+			continue
+		}
+		unknown += dbGroup.Count
+	}
+	if unknown > 0 {
+		groups = append(groups, entity.UserGroup{Key: entity.UnknownGroup, Count: unknown})
+	}
+	return groups, nil
+}
+
+// bucketIndex finds the bucket whose lower bound is the key of a $bucket group
+func bucketIndex(buckets []int, key interface{}) (int, bool) {
+	var bound int
+	switch value := key.(type) {
+	case int32:
+		bound = int(value)
+	case int64:
+		bound = int(value)
+	case float64:
+		bound = int(value)
+	default:
+		return 0, false
+	}
+	for i, bucket := range buckets {
+		if bucket == bound {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
 func (repo UserServiceMongo) Delete(id string) error {
 	return repo._db.Delete("users", id)
 }
+
+func (repo UserServiceMongo) Transaction(fn func(tx UserService) error) error {
+	return repo._db.Transaction(func(tx database.MongoDB) error {
+		return fn(&UserServiceMongo{_db: tx, config: repo.config})
+	})
+}
+
+// AddEvent stores the event in the outbox through the same database, inside a
+// transaction it is only published if the change is committed
+func (repo UserServiceMongo) AddEvent(event entity.UserEvent) error {
+	_, err := NewOutboxServiceMongo(repo._db, repo.config).Add(event)
+	return err
+}
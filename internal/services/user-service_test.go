@@ -2,8 +2,10 @@ package services
 
 import (
 	"log"
+	"math"
 	"os"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/Shodocan/UserService/internal/configs"
@@ -14,6 +16,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/mgo.v2/bson"
 )
 
 func checkMongo() bool {
@@ -358,33 +361,405 @@ func TestMongoRepositoryQuery(t *testing.T) {
 		createdUsers = append(createdUsers, createdUser)
 	}
 
-	foundUsers, pagination, err := repo.Query([]entity.UserFilter{{Field: "name", Operator: entity.Like, Value: "Skywalker"}}, []string{"-name", "age"}, 1, 100)
+	foundUsers, pagination, err := repo.Query(entity.AllOf(entity.UserFilter{Field: "name", Operator: entity.Like, Value: "Skywalker"}), []entity.SortRule{{Field: entity.Name, Descending: true}, {Field: entity.Age}}, 1, 100, false)
 	assert.Nil(t, err, "Must query")
 	assert.Len(t, foundUsers, 2, "Should find all skywalkers")
 	assert.Equal(t, "Luke Skywalker", foundUsers[0].Name, "Luke should be the first")
 	assert.NotNil(t, pagination)
 
-	foundUsers, pagination, err = repo.Query([]entity.UserFilter{}, []string{"name", "age"}, 1, 2)
+	foundUsers, pagination, err = repo.Query(entity.UserQuery{}, []entity.SortRule{{Field: entity.Name}, {Field: entity.Age}}, 1, 2, false)
 	assert.Nil(t, err, "Must query")
 	assert.Len(t, foundUsers, 2, "Should find 2")
 	assert.Equal(t, "Anakin Skywalker", foundUsers[0].Name, "Anakin should be the first")
 	assert.Equal(t, "Luke Skywalker", foundUsers[1].Name, "Luke should be the second")
 	assert.NotNil(t, pagination)
 
-	foundUsers, pagination, err = repo.Query([]entity.UserFilter{}, []string{"name", "age"}, 2, 2)
+	foundUsers, pagination, err = repo.Query(entity.UserQuery{}, []entity.SortRule{{Field: entity.Name}, {Field: entity.Age}}, 2, 2, false)
 	assert.Nil(t, err, "Must query")
 	assert.Len(t, foundUsers, 2, "Should find 2")
 	assert.Equal(t, "Samara Casonatto", foundUsers[0].Name, "Samara should be the first")
 	assert.Equal(t, "Walisson Casonatto", foundUsers[1].Name, "Walisson should be the second")
 	assert.NotNil(t, pagination)
 
-	foundUsers, pagination, err = repo.Query([]entity.UserFilter{}, []string{"name", "age"}, 3, 2)
+	byName := []entity.SortRule{{Field: entity.Name}, {Field: entity.Age}}
+	foundUsers, pagination, err = repo.Query(entity.UserQuery{}, byName, 1, 3, true)
+	assert.Nil(t, err, "Must query")
+	assert.Len(t, foundUsers, 3, "Should find 3")
+	assert.False(t, pagination.Counted(), "Should skip the total")
+	assert.True(t, pagination.HasNext, "Walisson is on the next page")
+
+	_, pagination, err = repo.Query(entity.UserQuery{}, byName, 1, 2, false)
+	assert.Nil(t, err, "Must query")
+	assert.NotEmpty(t, pagination.Next, "Should point to the second page")
+	cursor, err := entity.DecodeCursor(pagination.Next, byName)
+	assert.Nil(t, err)
+	foundUsers, pagination, err = repo.Scroll(entity.UserQuery{}, byName, cursor, 2, false)
+	assert.Nil(t, err, "Must scroll")
+	assert.Len(t, foundUsers, 2, "Should find 2")
+	assert.Equal(t, "Samara Casonatto", foundUsers[0].Name, "Samara should follow Luke")
+	assert.Empty(t, pagination.Next, "Walisson is the last")
+	cursor, err = entity.DecodeCursor(pagination.Prev, byName)
+	assert.Nil(t, err)
+	foundUsers, _, err = repo.Scroll(entity.UserQuery{}, byName, cursor, 2, false)
+	assert.Nil(t, err, "Must scroll back")
+	assert.Len(t, foundUsers, 2, "Should find 2")
+	assert.Equal(t, "Anakin Skywalker", foundUsers[0].Name, "Anakin should be the first")
+
+	foundUsers, pagination, err = repo.Query(entity.UserQuery{}, []entity.SortRule{{Field: entity.Name}, {Field: entity.Age}}, 3, 2, false)
 	assert.Nil(t, err, "Must query")
 	assert.Len(t, foundUsers, 0, "Should find 0")
 	assert.NotNil(t, pagination)
 
+	foundUsers, _, err = repo.Query(entity.AllOf(
+		entity.UserFilter{Field: "age", Operator: entity.GreaterOrEqual, Value: 24.0},
+		entity.UserFilter{Field: "age", Operator: entity.Less, Value: "30"},
+		entity.UserFilter{Field: "name", Operator: entity.NotIn, Value: []interface{}{"Luke Skywalker"}},
+	), []entity.SortRule{{Field: entity.Name}}, 1, 100, false)
+	assert.Nil(t, err, "Must query")
+	assert.Len(t, foundUsers, 2, "Should find the users between 24 and 30 but Luke")
+	assert.Equal(t, "Samara Casonatto", foundUsers[0].Name)
+
+	foundUsers, _, err = repo.Query(entity.AllOf(entity.UserFilter{Field: "name", Operator: entity.Prefix, Value: "Anakin"}), nil, 1, 100, false)
+	assert.Nil(t, err, "Must query")
+	assert.Len(t, foundUsers, 1, "Should find Anakin")
+
 	for _, user := range createdUsers {
 		err = repo.Delete(user.ID)
 		assert.Nil(t, err, "Should delete user")
 	}
 }
+
+func TestMongoQueryFilterOperators(t *testing.T) {
+	repo := UserServiceMongo{}
+	filter, err := repo.queryFilter(entity.AllOf(
+		entity.UserFilter{Field: "age", Operator: entity.Greater, Value: 18.0},
+		entity.UserFilter{Field: "age", Operator: entity.LessOrEqual, Value: "30"},
+		entity.UserFilter{Field: "email", Operator: entity.In, Value: []interface{}{"a@b.com"}},
+		entity.UserFilter{Field: "address", Operator: entity.Exists, Value: true},
+		entity.UserFilter{Field: "name", Operator: entity.Prefix, Value: "Wal.("},
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{"$and": []bson.M{
+		{"age": bson.M{"$gt": 18}},
+		{"age": bson.M{"$lte": 30}},
+		{"email": bson.M{"$in": []interface{}{"a@b.com"}}},
+		{"address": bson.M{"$exists": true}},
+		{"name": bson.M{"$regex": `^Wal\.\(`}},
+	}}, filter)
+
+	_, err = repo.queryFilter(entity.AllOf(entity.UserFilter{Field: "password", Operator: entity.Equal, Value: "secret"}))
+	assert.NotNil(t, err, "invalid filters must not be dropped")
+}
+
+func TestMongoQueryFilterExpression(t *testing.T) {
+	repo := UserServiceMongo{}
+	filter, err := repo.queryFilter(entity.UserQuery{Or: []entity.UserQuery{
+		{Filter: &entity.UserFilter{Field: "name", Operator: entity.Prefix, Value: "Luke"}},
+		{And: []entity.UserQuery{
+			{Filter: &entity.UserFilter{Field: "age", Operator: entity.Greater, Value: 40.0}},
+			{Not: &entity.UserQuery{Filter: &entity.UserFilter{Field: "email", Operator: entity.Exists, Value: true}}},
+		}},
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{"$or": []bson.M{
+		{"name": bson.M{"$regex": "^Luke"}},
+		{"$and": []bson.M{
+			{"age": bson.M{"$gt": 40}},
+			{"$nor": []bson.M{{"email": bson.M{"$exists": true}}}},
+		}},
+	}}, filter)
+
+	filter, err = repo.queryFilter(entity.UserQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{}, filter)
+}
+
+func TestMongoQueryFilterPatterns(t *testing.T) {
+	repo := UserServiceMongo{}
+	patterns := map[entity.FilterOperation]bson.M{
+		entity.Like:       {"$regex": `a\.b\*`},
+		entity.Contains:   {"$regex": `a\.b\*`, "$options": "i"},
+		entity.StartsWith: {"$regex": `^a\.b\*`},
+		entity.EndsWith:   {"$regex": `a\.b\*$`},
+		entity.IEquals:    {"$regex": `^a\.b\*$`, "$options": "i"},
+		entity.Regex:      {"$regex": `a.b*`},
+	}
+	for op, expected := range patterns {
+		filter, err := repo.queryFilter(entity.AllOf(entity.UserFilter{
+			Field: "name", Operator: op, Value: "a.b*", IgnoreCase: op == entity.Contains,
+		}))
+		assert.Nil(t, err, op)
+		assert.Equal(t, bson.M{"name": expected}, filter, op)
+	}
+}
+
+func TestMongoSortTiebreaker(t *testing.T) {
+	repo := UserServiceMongo{}
+
+	sort := repo.sort([]entity.SortRule{{Field: entity.Age, Descending: true}})
+	assert.Equal(t, primitive.D{{Key: "age", Value: -1}, {Key: "_id", Value: 1}}, sort)
+
+	sort = repo.sort([]entity.SortRule{{Field: entity.Name}})
+	assert.Equal(t, primitive.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}}, sort)
+
+	sort = repo.sort(nil)
+	assert.Equal(t, primitive.D{{Key: "_id", Value: 1}}, sort, "pages must be stable without a sort")
+}
+
+func TestMongoCollation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoMock := database.NewMockMongoDB(ctrl)
+	repo := UserServiceMongo{_db: mongoMock}
+	filter := bson.M{"email": bson.M{"$eq": "LUKE@REBELS.ORG"}}
+	query := entity.AllOf(entity.UserFilter{Field: "email", Operator: entity.Equal, Value: "LUKE@REBELS.ORG"})
+
+	assert.Equal(t, 2, userCollation.Strength, "names and emails must compare ignoring their case")
+	collated := database.CollatedFilter{Filter: filter, Collation: userCollation}
+	for _, sortList := range [][]entity.SortRule{nil, {{Field: entity.Age}}, {{Field: entity.Email}}} {
+		// the same users match whatever the sort
+		mongoMock.EXPECT().Query("users", collated, gomock.Any(), 0, 11, gomock.Any(), gomock.Any()).Return(nil)
+		mongoMock.EXPECT().Total("users", collated).Return(0, nil)
+		_, _, err := repo.Query(query, sortList, 1, 10, false)
+		assert.Nil(t, err)
+	}
+}
+
+func TestMongoKeyset(t *testing.T) {
+	repo := UserServiceMongo{}
+	id := primitive.NewObjectID()
+	rules := []entity.SortRule{{Field: entity.Name, Descending: true}, {Field: entity.Age}}
+
+	keyset, err := repo.keyset(rules, entity.Cursor{Values: []interface{}{"Luke", 24}, ID: id.Hex()})
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{"$or": []bson.M{
+		{"name": bson.M{"$lt": "Luke"}},
+		{"name": "Luke", "age": bson.M{"$gt": 24}},
+		{"name": "Luke", "age": 24, "_id": bson.M{"$gt": id}},
+	}}, keyset)
+
+	keyset, err = repo.keyset(rules, entity.Cursor{Values: []interface{}{"Luke", 24}, ID: id.Hex(), Backward: true})
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{"$or": []bson.M{
+		{"name": bson.M{"$gt": "Luke"}},
+		{"name": "Luke", "age": bson.M{"$lt": 24}},
+		{"name": "Luke", "age": 24, "_id": bson.M{"$lt": id}},
+	}}, keyset)
+
+	_, err = repo.keyset(rules, entity.Cursor{Values: []interface{}{"Luke", 24}, ID: "luke"})
+	assert.NotNil(t, err)
+
+	sort := repo.sort(rules)
+	assert.Equal(t, primitive.D{{Key: "name", Value: 1}, {Key: "age", Value: -1}, {Key: "_id", Value: -1}}, reversed(sort))
+}
+
+func TestMongoProjection(t *testing.T) {
+	assert.Equal(t, []string{"_id", "name", "age", "email", "address"}, projection(entity.UserQuery{}, nil, nil), "the password must not be read")
+	assert.Equal(t, []string{"_id", "email", "age"}, projection(entity.UserQuery{}, []entity.UserFied{entity.ID, entity.Email}, []entity.SortRule{{Field: entity.Age}, {Field: entity.Email}}))
+	assert.Equal(t, []string{"_id"}, projection(entity.UserQuery{}, []entity.UserFied{entity.ID}, nil), "fields=id must still project")
+	assert.Equal(t, []string{"_id", "name", "score"}, projection(entity.UserQuery{Text: "luke"}, []entity.UserFied{entity.Name}, []entity.SortRule{{Field: entity.Score}}), "text searches must read the score")
+}
+
+func TestMongoTextSearch(t *testing.T) {
+	repo := UserServiceMongo{}
+	query := entity.AllOf(entity.UserFilter{Field: "age", Operator: entity.Greater, Value: 18.0})
+	query.Text = "skywalker"
+	filter, err := repo.queryFilter(query)
+	assert.Nil(t, err)
+	assert.Equal(t, bson.M{"age": bson.M{"$gt": 18}, "$text": bson.M{"$search": "skywalker"}}, filter)
+
+	sort := repo.sort([]entity.SortRule{{Field: entity.Score}, {Field: entity.Name}})
+	assert.Equal(t, primitive.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}}, sort)
+	assert.Equal(t, filter, collate(filter), "text indexes don't support collations")
+	assert.IsType(t, bson.M{}, collate(bson.M{"$and": []bson.M{filter, {}}}))
+
+	_, _, err = repo.Scroll(query, []entity.SortRule{{Field: entity.Score}}, entity.Cursor{}, 10, true)
+	assert.NotNil(t, err, "relevance can't be paginated by cursor")
+}
+
+// aggregateCandidates runs the candidate pipeline of a fuzzy search over users, ranking
+// them by the grams they share with the search and keeping as many as its limit
+func aggregateCandidates(users DBUserList) func(namespace string, pipeline interface{}, dst interface{}) error {
+	return func(namespace string, pipeline interface{}, dst interface{}) error {
+		stages := pipeline.([]interface{})
+		grams := stages[0].(bson.M)["$match"].(bson.M)["grams"].(bson.M)["$in"].([]string)
+		limit := stages[3].(bson.M)["$limit"].(int)
+		shared := map[primitive.ObjectID]int{}
+		matched := DBUserList{}
+		for _, user := range users {
+			for _, gram := range grams {
+				for _, userGram := range user.Grams {
+					if gram == userGram {
+						shared[user.ID]++
+					}
+				}
+			}
+			if shared[user.ID] > 0 {
+				matched = append(matched, user)
+			}
+		}
+		sort.SliceStable(matched, func(i, j int) bool {
+			if shared[matched[i].ID] != shared[matched[j].ID] {
+				return shared[matched[i].ID] > shared[matched[j].ID]
+			}
+			return matched[i].ID.Hex() < matched[j].ID.Hex()
+		})
+		if len(matched) > limit {
+			matched = matched[:limit]
+		}
+		*dst.(*DBUserList) = matched
+		return nil
+	}
+}
+
+func TestMongoFuzzy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoMock := database.NewMockMongoDB(ctrl)
+	repo := UserServiceMongo{_db: mongoMock}
+
+	// the oldest users only share the common grams, the best match is the newest one
+	users := DBUserList{}
+	for _, user := range []entity.User{
+		{Name: "Sam Walker", Email: "sam@walker.com"},
+		{Name: "Luna Sky", Email: "luna@sky.com"},
+		{Name: "Leia Organa", Email: "leia@rebels.org"},
+		{Name: "Luke Skywalker", Email: "luke@rebels.org"},
+	} {
+		dbUser := MapDBUser(user)
+		dbUser.ID = primitive.NewObjectID()
+		users = append(users, *dbUser)
+	}
+	mongoMock.EXPECT().Aggregate("users", gomock.Any(), gomock.Any()).DoAndReturn(aggregateCandidates(users)).Times(2)
+
+	found, err := repo.Fuzzy(entity.FuzzySearch{Text: "luke skywalkr", Threshold: 0.3, Limit: 1, Candidates: 2})
+	assert.Nil(t, err)
+	assert.Len(t, found, 1, "the limit must be kept")
+	assert.Equal(t, "Luke Skywalker", found[0].Name, "the closest name must be first, even past the oldest candidates")
+	assert.Greater(t, found[0].Score, 0.5)
+
+	found, err = repo.Fuzzy(entity.FuzzySearch{Text: "leia organa", Threshold: 0.3, Limit: 10, Candidates: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "Leia Organa", found[0].Name)
+
+	found, err = repo.Fuzzy(entity.FuzzySearch{Text: "..", Threshold: 0.3, Limit: 10, Candidates: 50})
+	assert.Nil(t, err)
+	assert.Empty(t, found, "a text without words finds nobody")
+}
+
+func TestFuzzyCandidatePipeline(t *testing.T) {
+	stages := candidatePipeline([]string{" sk", "sky"}, 50)
+	assert.Equal(t, bson.M{"$sort": primitive.D{{Key: "shared", Value: -1}, {Key: "_id", Value: 1}}}, stages[2], "the candidates must be ranked before being limited")
+	assert.Equal(t, bson.M{"$limit": 50}, stages[3])
+	assert.NotContains(t, stages[1].(bson.M)["$project"], "password")
+	assert.NotContains(t, stages[1].(bson.M)["$project"], "grams")
+}
+
+func TestMapDBUserGrams(t *testing.T) {
+	dbUser := MapDBUser(entity.User{Name: "Luke", Email: "l@r.org"})
+	assert.Contains(t, dbUser.Grams, "luk")
+	assert.Contains(t, dbUser.Grams, "org")
+}
+
+func TestPartialUpdateGrams(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoMock := database.NewMockMongoDB(ctrl)
+	repo := UserServiceMongo{_db: mongoMock}
+	userID := primitive.NewObjectID().Hex()
+
+	gomock.InOrder(
+		mongoMock.EXPECT().Find("users", userID, gomock.Any()).DoAndReturn(func(namespace, id string, dst interface{}, fields ...string) error {
+			dst.(*DBUser).Name = "Luke"
+			dst.(*DBUser).Email = "luke@rebels.org"
+			return nil
+		}),
+		mongoMock.EXPECT().Update("users", userID, gomock.Any()).DoAndReturn(func(namespace, id string, data interface{}) error {
+			grams := data.(*DBUserPartial).Grams
+			assert.Contains(t, grams, "han", "the new name must be searchable")
+			assert.Contains(t, grams, "reb", "the email must stay searchable")
+			return nil
+		}),
+		mongoMock.EXPECT().Find("users", userID, gomock.Any()).Return(nil),
+	)
+
+	_, err := repo.PartialUpdate(userID, entity.User{Name: "Han"})
+	assert.Nil(t, err)
+}
+
+// stagesOf takes the collation and time limit off an aggregation pipeline
+func stagesOf(pipeline interface{}) []interface{} {
+	switch wrapper := pipeline.(type) {
+	case database.CollatedFilter:
+		return stagesOf(wrapper.Filter)
+	case database.TimeLimited:
+		return stagesOf(wrapper.Query)
+	}
+	return pipeline.([]interface{})
+}
+
+func TestMongoAggregateAge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoMock := database.NewMockMongoDB(ctrl)
+	repo := UserServiceMongo{_db: mongoMock}
+
+	mongoMock.EXPECT().Aggregate("users", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(namespace string, stages interface{}, dst interface{}) error {
+			bucket := stagesOf(stages)[1].(bson.M)["$bucket"].(bson.M)
+			assert.Equal(t, []interface{}{0, 18, 65, math.MaxInt32}, bucket["boundaries"])
+			*dst.(*[]DBGroup) = []DBGroup{{Key: int32(18), Count: 7}, {Key: int32(65), Count: 2}, {Key: entity.UnknownGroup, Count: 1}}
+			return nil
+		})
+
+	groups, err := repo.Aggregate(entity.UserAggregation{GroupBy: entity.GroupByAge, Buckets: []int{0, 18, 65}})
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.UserGroup{{Key: "0-17"}, {Key: "18-64", Count: 7}, {Key: "65+", Count: 2}, {Key: entity.UnknownGroup, Count: 1}}, groups)
+}
+
+func TestMongoAggregateEmailDomain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoMock := database.NewMockMongoDB(ctrl)
+	repo := UserServiceMongo{_db: mongoMock}
+
+	query := entity.AllOf(entity.UserFilter{Field: "age", Operator: entity.Greater, Value: 18.0})
+	mongoMock.EXPECT().Aggregate("users", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(namespace string, stages interface{}, dst interface{}) error {
+			assert.Equal(t, bson.M{"$match": bson.M{"age": bson.M{"$gt": 18}}}, stagesOf(stages)[0], "the filters must run first")
+			*dst.(*[]DBGroup) = []DBGroup{{Key: "rebels.org", Count: 3}, {Key: nil, Count: 1}, {Key: "", Count: 1}}
+			return nil
+		})
+
+	groups, err := repo.Aggregate(entity.UserAggregation{Query: query, GroupBy: entity.GroupByEmailDomain})
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.UserGroup{{Key: "rebels.org", Count: 3}, {Key: entity.UnknownGroup, Count: 2}}, groups)
+}
+
+func TestMongoRegexQueriesAreTimeLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mongoMock := database.NewMockMongoDB(ctrl)
+	repo := UserServiceMongo{_db: mongoMock}
+
+	filter := database.CollatedFilter{Filter: bson.M{"name": bson.M{"$regex": "^Luke"}}, Collation: userCollation}
+	limited := database.TimeLimited{Query: filter, MaxTime: regexMaxTime}
+	query := entity.AllOf(entity.UserFilter{Field: "name", Operator: entity.Regex, Value: "^Luke"})
+	mongoMock.EXPECT().Query("users", limited, gomock.Any(), 0, 11, gomock.Any(), gomock.Any()).Return(nil)
+	mongoMock.EXPECT().Total("users", limited).Return(0, nil)
+	_, _, err := repo.Query(query, nil, 1, 10, false)
+	assert.Nil(t, err)
+
+	mongoMock.EXPECT().Aggregate("users", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(namespace string, stages interface{}, dst interface{}) error {
+			assert.Equal(t, regexMaxTime, stages.(database.TimeLimited).MaxTime)
+			return nil
+		})
+	_, err = repo.Aggregate(entity.UserAggregation{Query: query, GroupBy: entity.GroupByCity})
+	assert.Nil(t, err)
+
+	mongoMock.EXPECT().Total("users", filter).Return(0, nil)
+	mongoMock.EXPECT().Query("users", gomock.Any(), gomock.Any(), 0, 11, gomock.Any(), gomock.Any()).Return(nil)
+	_, _, err = repo.Query(entity.AllOf(entity.UserFilter{Field: "name", Operator: entity.StartsWith, Value: "Luke"}), nil, 1, 10, false)
+	assert.Nil(t, err, "escaped patterns are not limited")
+}
@@ -7,10 +7,21 @@ import (
 
 //go:generate mockgen -destination user-repository_mock.go -package services . UserService
 type UserService interface {
-	Query(filters []entity.UserFilter, sortList []string, page, limit int) ([]entity.User, engine.Pagination, error)
+	// Query lists a page of the users, skipTotal leaves them uncounted for expensive queries.
+	// Listed users are read without their password and with only the fields given, if any
+	Query(query entity.UserQuery, sortList []entity.SortRule, page, limit int, skipTotal bool, fields ...entity.UserFied) ([]entity.User, engine.Pagination, error)
+	// Scroll lists limit users after the cursor, or before it for backward cursors
+	Scroll(query entity.UserQuery, sortList []entity.SortRule, cursor entity.Cursor, limit int, skipTotal bool, fields ...entity.UserFied) ([]entity.User, engine.Pagination, error)
+	// Fuzzy ranks the users whose name or email look like the text, most similar first
+	Fuzzy(search entity.FuzzySearch) ([]entity.User, error)
+	// Aggregate counts the users matching the query by the grouping
+	Aggregate(aggregation entity.UserAggregation) ([]entity.UserGroup, error)
 	Create(data entity.User) (entity.User, error)
-	Find(id string) (entity.User, error)
+	Find(id string, fields ...entity.UserFied) (entity.User, error)
 	Update(id string, user entity.User) (entity.User, error)
 	PartialUpdate(id string, user entity.User) (entity.User, error)
 	Delete(id string) error
+	// Transaction runs fn with a service whose writes, including events, are committed together
+	Transaction(fn func(tx UserService) error) error
+	AddEvent(event entity.UserEvent) error
 }
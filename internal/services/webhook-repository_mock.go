@@ -0,0 +1,186 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/Shodocan/UserService/internal/services (interfaces: WebhookService)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	reflect "reflect"
+
+	engine "github.com/Shodocan/UserService/internal/configs/engine"
+	entity "github.com/Shodocan/UserService/internal/domain/entity"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// AddDelivery mocks base method.
+func (m *MockWebhookService) AddDelivery(arg0 entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDelivery", arg0)
+	ret0, _ := ret[0].(entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddDelivery indicates an expected call of AddDelivery.
+func (mr *MockWebhookServiceMockRecorder) AddDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDelivery", reflect.TypeOf((*MockWebhookService)(nil).AddDelivery), arg0)
+}
+
+// CreateSubscription mocks base method.
+func (m *MockWebhookService) CreateSubscription(arg0 entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", arg0)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockWebhookServiceMockRecorder) CreateSubscription(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockWebhookService)(nil).CreateSubscription), arg0)
+}
+
+// DeleteSubscription mocks base method.
+func (m *MockWebhookService) DeleteSubscription(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubscription", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubscription indicates an expected call of DeleteSubscription.
+func (mr *MockWebhookServiceMockRecorder) DeleteSubscription(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubscription", reflect.TypeOf((*MockWebhookService)(nil).DeleteSubscription), arg0)
+}
+
+// Deliveries mocks base method.
+func (m *MockWebhookService) Deliveries(arg0 string, arg1 entity.DeliveryStatus, arg2, arg3 int) ([]entity.WebhookDelivery, engine.Pagination, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deliveries", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]entity.WebhookDelivery)
+	ret1, _ := ret[1].(engine.Pagination)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Deliveries indicates an expected call of Deliveries.
+func (mr *MockWebhookServiceMockRecorder) Deliveries(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deliveries", reflect.TypeOf((*MockWebhookService)(nil).Deliveries), arg0, arg1, arg2, arg3)
+}
+
+// FindDelivery mocks base method.
+func (m *MockWebhookService) FindDelivery(arg0 string) (entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDelivery", arg0)
+	ret0, _ := ret[0].(entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDelivery indicates an expected call of FindDelivery.
+func (mr *MockWebhookServiceMockRecorder) FindDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDelivery", reflect.TypeOf((*MockWebhookService)(nil).FindDelivery), arg0)
+}
+
+// FindSubscription mocks base method.
+func (m *MockWebhookService) FindSubscription(arg0 string) (entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSubscription", arg0)
+	ret0, _ := ret[0].(entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSubscription indicates an expected call of FindSubscription.
+func (mr *MockWebhookServiceMockRecorder) FindSubscription(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSubscription", reflect.TypeOf((*MockWebhookService)(nil).FindSubscription), arg0)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockWebhookService) ListSubscriptions(arg0, arg1 int) ([]entity.WebhookSubscription, engine.Pagination, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", arg0, arg1)
+	ret0, _ := ret[0].([]entity.WebhookSubscription)
+	ret1, _ := ret[1].(engine.Pagination)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockWebhookServiceMockRecorder) ListSubscriptions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockWebhookService)(nil).ListSubscriptions), arg0, arg1)
+}
+
+// PendingDeliveries mocks base method.
+func (m *MockWebhookService) PendingDeliveries(arg0 int) ([]entity.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingDeliveries", arg0)
+	ret0, _ := ret[0].([]entity.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingDeliveries indicates an expected call of PendingDeliveries.
+func (mr *MockWebhookServiceMockRecorder) PendingDeliveries(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingDeliveries", reflect.TypeOf((*MockWebhookService)(nil).PendingDeliveries), arg0)
+}
+
+// SubscriptionsFor mocks base method.
+func (m *MockWebhookService) SubscriptionsFor(arg0 entity.UserEventType) ([]entity.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscriptionsFor", arg0)
+	ret0, _ := ret[0].([]entity.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscriptionsFor indicates an expected call of SubscriptionsFor.
+func (mr *MockWebhookServiceMockRecorder) SubscriptionsFor(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionsFor", reflect.TypeOf((*MockWebhookService)(nil).SubscriptionsFor), arg0)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhookService) UpdateDelivery(arg0 entity.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhookServiceMockRecorder) UpdateDelivery(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhookService)(nil).UpdateDelivery), arg0)
+}
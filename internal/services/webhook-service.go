@@ -0,0 +1,244 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	webhookNamespace  = "webhooks"
+	deliveryNamespace = "webhook_deliveries"
+)
+
+func NewWebhookServiceMongo(db database.MongoDB, config *configs.EnvVarConfig) WebhookService {
+	// subscriptions and deliveries change under the workers, they must never come from a cache
+	return &WebhookServiceMongo{_db: database.SourceOf(db), config: config}
+}
+
+type WebhookServiceMongo struct {
+	_db    database.MongoDB
+	config *configs.EnvVarConfig
+}
+
+type DBWebhookList []DBWebhook
+
+type DBWebhook struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	URL        string             `bson:"url"`
+	EventTypes []string           `bson:"eventTypes"`
+	Secret     string             `bson:"secret"`
+	CreatedAt  time.Time          `bson:"createdAt"`
+}
+
+func MapDBWebhook(subscription entity.WebhookSubscription) *DBWebhook {
+	eventTypes := []string{}
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+	return &DBWebhook{
+		URL:        subscription.URL,
+		EventTypes: eventTypes,
+		Secret:     subscription.Secret,
+		CreatedAt:  subscription.CreatedAt,
+	}
+}
+
+func (dbWebhook *DBWebhook) ToSubscription() entity.WebhookSubscription {
+	eventTypes := []entity.UserEventType{}
+	for _, eventType := range dbWebhook.EventTypes {
+		eventTypes = append(eventTypes, entity.UserEventType(eventType))
+	}
+	return entity.WebhookSubscription{
+		ID:         dbWebhook.ID.Hex(),
+		URL:        dbWebhook.URL,
+		EventTypes: eventTypes,
+		Secret:     dbWebhook.Secret,
+		CreatedAt:  dbWebhook.CreatedAt.UTC(),
+	}
+}
+
+func (list DBWebhookList) ToSubscriptionList() []entity.WebhookSubscription {
+	result := []entity.WebhookSubscription{}
+	for _, subscription := range list {
+		result = append(result, subscription.ToSubscription())
+	}
+	return result
+}
+
+type DBDeliveryList []DBDelivery
+
+type DBDelivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	SubscriptionID string             `bson:"subscriptionId"`
+	EventID        string             `bson:"eventId"`
+	EventType      string             `bson:"eventType"`
+	Payload        string             `bson:"payload"`
+	Status         string             `bson:"status"`
+	Attempts       int                `bson:"attempts"`
+	NextAttemptAt  time.Time          `bson:"nextAttemptAt"`
+	LastStatusCode int                `bson:"lastStatusCode"`
+	LastError      string             `bson:"lastError"`
+	CreatedAt      time.Time          `bson:"createdAt"`
+	DeliveredAt    *time.Time         `bson:"deliveredAt,omitempty"`
+}
+
+func MapDBDelivery(delivery entity.WebhookDelivery) *DBDelivery {
+	return &DBDelivery{
+		SubscriptionID: delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      string(delivery.EventType),
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
+
+func (dbDelivery *DBDelivery) ToDelivery() entity.WebhookDelivery {
+	return entity.WebhookDelivery{
+		ID:             dbDelivery.ID.Hex(),
+		SubscriptionID: dbDelivery.SubscriptionID,
+		EventID:        dbDelivery.EventID,
+		EventType:      entity.UserEventType(dbDelivery.EventType),
+		Payload:        dbDelivery.Payload,
+		Status:         entity.DeliveryStatus(dbDelivery.Status),
+		Attempts:       dbDelivery.Attempts,
+		NextAttemptAt:  dbDelivery.NextAttemptAt.UTC(),
+		LastStatusCode: dbDelivery.LastStatusCode,
+		LastError:      dbDelivery.LastError,
+		CreatedAt:      dbDelivery.CreatedAt.UTC(),
+		DeliveredAt:    dbDelivery.DeliveredAt,
+	}
+}
+
+func (list DBDeliveryList) ToDeliveryList() []entity.WebhookDelivery {
+	result := []entity.WebhookDelivery{}
+	for _, delivery := range list {
+		result = append(result, delivery.ToDelivery())
+	}
+	return result
+}
+
+func (repo WebhookServiceMongo) CreateSubscription(subscription entity.WebhookSubscription) (entity.WebhookSubscription, error) {
+	id, err := repo._db.Create(webhookNamespace, MapDBWebhook(subscription))
+	if err != nil {
+		return entity.WebhookSubscription{}, err
+	}
+	return repo.FindSubscription(id)
+}
+
+func (repo WebhookServiceMongo) FindSubscription(id string) (entity.WebhookSubscription, error) {
+	dbWebhook := &DBWebhook{}
+	err := repo._db.Find(webhookNamespace, id, dbWebhook)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return entity.WebhookSubscription{}, ErrSubscriptionNotFound
+	}
+	return dbWebhook.ToSubscription(), err
+}
+
+func (repo WebhookServiceMongo) ListSubscriptions(page, limit int) ([]entity.WebhookSubscription, engine.Pagination, error) {
+	total, err := repo._db.Total(webhookNamespace, bson.M{})
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	dbWebhooks := DBWebhookList{}
+	err = repo._db.Query(webhookNamespace, bson.M{}, primitive.D{{Key: "_id", Value: 1}}, (page*limit)-limit, limit, &dbWebhooks)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	return dbWebhooks.ToSubscriptionList(), engine.NewPagination(total, len(dbWebhooks), page, limit), nil
+}
+
+func (repo WebhookServiceMongo) DeleteSubscription(id string) error {
+	err := repo._db.Delete(webhookNamespace, id)
+	if err == primitive.ErrInvalidHex {
+		return ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func (repo WebhookServiceMongo) SubscriptionsFor(eventType entity.UserEventType) ([]entity.WebhookSubscription, error) {
+	filter := bson.M{"$or": []bson.M{
+		{"eventTypes": string(eventType)},
+		{"eventTypes": bson.M{"$size": 0}},
+	}}
+	dbWebhooks := DBWebhookList{}
+	err := repo._db.Query(webhookNamespace, filter, primitive.D{{Key: "_id", Value: 1}}, 0, 0, &dbWebhooks)
+	if err != nil {
+		return nil, err
+	}
+	return dbWebhooks.ToSubscriptionList(), nil
+}
+
+func (repo WebhookServiceMongo) AddDelivery(delivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	id, err := repo._db.Create(deliveryNamespace, MapDBDelivery(delivery))
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+	delivery.ID = id
+	return delivery, nil
+}
+
+func (repo WebhookServiceMongo) FindDelivery(id string) (entity.WebhookDelivery, error) {
+	dbDelivery := &DBDelivery{}
+	err := repo._db.Find(deliveryNamespace, id, dbDelivery)
+	if err == mongo.ErrNoDocuments || err == primitive.ErrInvalidHex {
+		return entity.WebhookDelivery{}, ErrDeliveryNotFound
+	}
+	return dbDelivery.ToDelivery(), err
+}
+
+func (repo WebhookServiceMongo) Deliveries(subscriptionID string, status entity.DeliveryStatus, page, limit int) ([]entity.WebhookDelivery, engine.Pagination, error) {
+	filter := bson.M{}
+	if subscriptionID != "" {
+		filter["subscriptionId"] = subscriptionID
+	}
+	if status != "" {
+		filter["status"] = string(status)
+	}
+	total, err := repo._db.Total(deliveryNamespace, filter)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	dbDeliveries := DBDeliveryList{}
+	err = repo._db.Query(deliveryNamespace, filter, primitive.D{{Key: "_id", Value: -1}}, (page*limit)-limit, limit, &dbDeliveries)
+	if err != nil {
+		return nil, engine.Pagination{}, err
+	}
+	return dbDeliveries.ToDeliveryList(), engine.NewPagination(total, len(dbDeliveries), page, limit), nil
+}
+
+func (repo WebhookServiceMongo) PendingDeliveries(limit int) ([]entity.WebhookDelivery, error) {
+	filter := bson.M{
+		"status":        string(entity.DeliveryPending),
+		"nextAttemptAt": bson.M{"$lte": time.Now().UTC()},
+	}
+	dbDeliveries := DBDeliveryList{}
+	err := repo._db.Query(deliveryNamespace, filter, primitive.D{{Key: "nextAttemptAt", Value: 1}}, 0, limit, &dbDeliveries)
+	if err != nil {
+		return nil, err
+	}
+	return dbDeliveries.ToDeliveryList(), nil
+}
+
+func (repo WebhookServiceMongo) UpdateDelivery(delivery entity.WebhookDelivery) error {
+	return repo._db.Update(deliveryNamespace, delivery.ID, bson.M{
+		"status":         string(delivery.Status),
+		"attempts":       delivery.Attempts,
+		"nextAttemptAt":  delivery.NextAttemptAt,
+		"lastStatusCode": delivery.LastStatusCode,
+		"lastError":      delivery.LastError,
+		"deliveredAt":    delivery.DeliveredAt,
+	})
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// ErrSubscriptionNotFound is returned when reading a subscription that does not exist or was removed
+var ErrSubscriptionNotFound = errors.New("webhook subscription not found")
+
+// ErrDeliveryNotFound is returned when reading a delivery that does not exist
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+//go:generate mockgen -destination webhook-repository_mock.go -package services . WebhookService
+type WebhookService interface {
+	CreateSubscription(subscription entity.WebhookSubscription) (entity.WebhookSubscription, error)
+	FindSubscription(id string) (entity.WebhookSubscription, error)
+	ListSubscriptions(page, limit int) ([]entity.WebhookSubscription, engine.Pagination, error)
+	DeleteSubscription(id string) error
+	SubscriptionsFor(eventType entity.UserEventType) ([]entity.WebhookSubscription, error)
+	AddDelivery(delivery entity.WebhookDelivery) (entity.WebhookDelivery, error)
+	FindDelivery(id string) (entity.WebhookDelivery, error)
+	Deliveries(subscriptionID string, status entity.DeliveryStatus, page, limit int) ([]entity.WebhookDelivery, engine.Pagination, error)
+	PendingDeliveries(limit int) ([]entity.WebhookDelivery, error)
+	UpdateDelivery(delivery entity.WebhookDelivery) error
+}
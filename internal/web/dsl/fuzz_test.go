@@ -0,0 +1,27 @@
+//go:build go1.18
+// +build go1.18
+
+package dsl
+
+import (
+	"testing"
+)
+
+func FuzzParse(f *testing.F) {
+	f.Add(`age>=18 and email~"@corp.com"`)
+	f.Add(`not (name in ["Luke", "Leia"] or address exists false)`)
+	f.Add(`name prefix "Sky\"walker" and age != -1`)
+	f.Fuzz(func(t *testing.T, input string) {
+		query, err := Parse(input)
+		if err != nil {
+			if _, ok := err.(*SyntaxError); !ok {
+				t.Fatalf("unexpected error type %T", err)
+			}
+			return
+		}
+		// whatever parses must be a query the search accepts
+		if errors := query.Errors("q"); len(errors) > 0 {
+			t.Fatalf("%q parsed into an invalid query %v", input, errors)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package dsl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenWord:
+		return "word"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	case tokenOperator:
+		return "operator"
+	case tokenLParen:
+		return "("
+	case tokenRParen:
+		return ")"
+	case tokenLBracket:
+		return "["
+	case tokenRBracket:
+		return "]"
+	default:
+		return ","
+	}
+}
+
+// token is a lexeme of the query, pos is its byte offset
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return t.kind.String()
+	}
+	return strconv.Quote(t.text)
+}
+
+// keyword reports whether the token is the bare word, keywords are case insensitive
+func (t token) keyword(word string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+type lexer struct {
+	input string
+	pos   int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) && isSpace(l.input[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if l.pos >= len(l.input) {
+		return token{kind: tokenEOF, pos: start}, nil
+	}
+
+	c := l.input[l.pos]
+	switch {
+	case c == '(':
+		l.pos++
+		return token{kind: tokenLParen, text: "(", pos: start}, nil
+	case c == ')':
+		l.pos++
+		return token{kind: tokenRParen, text: ")", pos: start}, nil
+	case c == '[':
+		l.pos++
+		return token{kind: tokenLBracket, text: "[", pos: start}, nil
+	case c == ']':
+		l.pos++
+		return token{kind: tokenRBracket, text: "]", pos: start}, nil
+	case c == ',':
+		l.pos++
+		return token{kind: tokenComma, text: ",", pos: start}, nil
+	case c == '"':
+		return l.string()
+	case c == '-' || isDigit(c):
+		return l.number()
+	case strings.IndexByte("=!<>~", c) >= 0:
+		return l.operator()
+	case isWordStart(c):
+		for l.pos < len(l.input) && isWordPart(l.input[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenWord, text: l.input[start:l.pos], pos: start}, nil
+	default:
+		return token{}, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", c)}
+	}
+}
+
+// string reads a double quoted string, \" and \\ are the only escapes
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	l.pos++
+	var value strings.Builder
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return token{kind: tokenString, text: l.input[start:l.pos], value: value.String(), pos: start}, nil
+		case '\\':
+			if l.pos+1 >= len(l.input) || (l.input[l.pos+1] != '"' && l.input[l.pos+1] != '\\') {
+				return token{}, &SyntaxError{Pos: l.pos, Msg: `invalid escape, only \" and \\ are allowed`}
+			}
+			value.WriteByte(l.input[l.pos+1])
+			l.pos += 2
+		default:
+			value.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, &SyntaxError{Pos: start, Msg: "unterminated string"}
+}
+
+// number reads a decimal number, returned as float64 like a JSON number
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.input[l.pos] == '-' {
+		l.pos++
+	}
+	for l.pos < len(l.input) && (isDigit(l.input[l.pos]) || l.input[l.pos] == '.') {
+		l.pos++
+	}
+	text := l.input[start:l.pos]
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, &SyntaxError{Pos: start, Msg: fmt.Sprintf("invalid number %q", text)}
+	}
+	return token{kind: tokenNumber, text: text, value: value, pos: start}, nil
+}
+
+func (l *lexer) operator() (token, error) {
+	start := l.pos
+	for _, op := range []string{"!=", ">=", "<=", "=", ">", "<", "~"} {
+		if strings.HasPrefix(l.input[l.pos:], op) {
+			l.pos += len(op)
+			return token{kind: tokenOperator, text: op, pos: start}, nil
+		}
+	}
+	return token{}, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unexpected character %q", l.input[start])}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isWordPart(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '.' || c == '@' || c == '-'
+}
@@ -0,0 +1,304 @@
+// Package dsl parses the compact filter language of the query string into a user query,
+// for example
+//
+//	age>=18 and (email~"@corp.com" or not address exists) and name in ["Luke", "Leia"]
+//
+// Comparisons are a field, an operator (=, !=, >, >=, <, <=, ~, in, nin, exists, prefix,
+// contains, startsWith, endsWith, iequals or regex) and a value: a double quoted string, a
+// number, true, false or a bare word. in and nin take a bracketed list and exists takes an
+// optional boolean, true by default. icontains, istartsWith and iendsWith ignore the case.
+// Operator keywords are case insensitive. not binds tighter than and, which binds tighter than or
+package dsl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// MaxLength bounds the query accepted by Parse
+const MaxLength = 2048
+
+// SyntaxError is an invalid query, Pos is the byte offset of the offending token
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos)
+}
+
+// wordOperators are the operators written as keywords, by lower case keyword
+var wordOperators = map[string]entity.FilterOperation{
+	"in":         entity.In,
+	"nin":        entity.NotIn,
+	"prefix":     entity.Prefix,
+	"exists":     entity.Exists,
+	"contains":   entity.Contains,
+	"startswith": entity.StartsWith,
+	"endswith":   entity.EndsWith,
+	"iequals":    entity.IEquals,
+	"regex":      entity.Regex,
+}
+
+// ignoreCaseOperators are the keywords of the case insensitive pattern operators
+var ignoreCaseOperators = map[string]entity.FilterOperation{
+	"icontains":   entity.Contains,
+	"istartswith": entity.StartsWith,
+	"iendswith":   entity.EndsWith,
+}
+
+// Parse parses a query, the empty query matches every user. Errors are *SyntaxError
+func Parse(input string) (entity.UserQuery, error) {
+	if len(input) > MaxLength {
+		return entity.UserQuery{}, &SyntaxError{Pos: MaxLength, Msg: fmt.Sprintf("query is longer than %d characters", MaxLength)}
+	}
+	p := &parser{lexer: lexer{input: input}}
+	err := p.advance()
+	if err != nil {
+		return entity.UserQuery{}, err
+	}
+	if p.token.kind == tokenEOF {
+		return entity.UserQuery{}, nil
+	}
+	query, err := p.or()
+	if err != nil {
+		return entity.UserQuery{}, err
+	}
+	if p.token.kind != tokenEOF {
+		return entity.UserQuery{}, p.unexpected("and, or")
+	}
+	return query, nil
+}
+
+type parser struct {
+	lexer lexer
+	token token
+	nodes int
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lexer.next()
+	if err != nil {
+		return err
+	}
+	p.token = tok
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	return &SyntaxError{Pos: p.token.pos, Msg: fmt.Sprintf("unexpected %s, expected %s", p.token, expected)}
+}
+
+// node counts the nodes of the query, so a huge query fails before it is built
+func (p *parser) node() error {
+	p.nodes++
+	if p.nodes > entity.MaxQueryNodes {
+		return &SyntaxError{Pos: p.token.pos, Msg: fmt.Sprintf("query has more than %d nodes", entity.MaxQueryNodes)}
+	}
+	return nil
+}
+
+// nested fails when the group starting at pos is nested too deep
+func nested(query entity.UserQuery, pos int) (entity.UserQuery, error) {
+	if height(query) > entity.MaxQueryDepth {
+		return entity.UserQuery{}, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("query is nested deeper than %d levels", entity.MaxQueryDepth)}
+	}
+	return query, nil
+}
+
+func height(query entity.UserQuery) int {
+	children := append(append([]entity.UserQuery{}, query.And...), query.Or...)
+	if query.Not != nil {
+		children = append(children, *query.Not)
+	}
+	max := 0
+	for _, child := range children {
+		if h := height(child); h > max {
+			max = h
+		}
+	}
+	return max + 1
+}
+
+func (p *parser) or() (entity.UserQuery, error) {
+	return p.group("or", p.and)
+}
+
+func (p *parser) and() (entity.UserQuery, error) {
+	return p.group("and", p.unary)
+}
+
+// group parses operands separated by the keyword, a single operand is returned as is
+func (p *parser) group(keyword string, operand func() (entity.UserQuery, error)) (entity.UserQuery, error) {
+	start := p.token.pos
+	first, err := operand()
+	if err != nil || !p.token.keyword(keyword) {
+		return first, err
+	}
+	err = p.node()
+	if err != nil {
+		return first, err
+	}
+
+	operands := []entity.UserQuery{first}
+	for p.token.keyword(keyword) {
+		err = p.advance()
+		if err != nil {
+			return first, err
+		}
+		next, err := operand()
+		if err != nil {
+			return first, err
+		}
+		operands = append(operands, next)
+	}
+	if keyword == "and" {
+		return nested(entity.UserQuery{And: operands}, start)
+	}
+	return nested(entity.UserQuery{Or: operands}, start)
+}
+
+func (p *parser) unary() (entity.UserQuery, error) {
+	start := p.token.pos
+	switch {
+	case p.token.keyword("not"):
+		err := p.node()
+		if err != nil {
+			return entity.UserQuery{}, err
+		}
+		err = p.advance()
+		if err != nil {
+			return entity.UserQuery{}, err
+		}
+		operand, err := p.unary()
+		if err != nil {
+			return entity.UserQuery{}, err
+		}
+		return nested(entity.UserQuery{Not: &operand}, start)
+	case p.token.kind == tokenLParen:
+		err := p.advance()
+		if err != nil {
+			return entity.UserQuery{}, err
+		}
+		query, err := p.or()
+		if err != nil {
+			return entity.UserQuery{}, err
+		}
+		if p.token.kind != tokenRParen {
+			return entity.UserQuery{}, p.unexpected(")")
+		}
+		return query, p.advance()
+	case p.token.kind == tokenWord:
+		return p.comparison()
+	default:
+		return entity.UserQuery{}, p.unexpected("a field, not or (")
+	}
+}
+
+func (p *parser) comparison() (entity.UserQuery, error) {
+	start := p.token.pos
+	err := p.node()
+	if err != nil {
+		return entity.UserQuery{}, err
+	}
+	filter := entity.UserFilter{Field: entity.UserFied(p.token.text)}
+	if _, ok := filter.Field.Type(); !ok {
+		return entity.UserQuery{}, &SyntaxError{Pos: start, Msg: fmt.Sprintf("unknown field %q", p.token.text)}
+	}
+	err = p.advance()
+	if err != nil {
+		return entity.UserQuery{}, err
+	}
+
+	keyword := strings.ToLower(p.token.text)
+	op, ok := wordOperators[keyword]
+	ignoreCaseOp, ignoreCase := ignoreCaseOperators[keyword]
+	switch {
+	case p.token.kind == tokenOperator:
+		filter.Operator = entity.FilterOperation(p.token.text)
+	case p.token.kind == tokenWord && ok:
+		filter.Operator = op
+	case p.token.kind == tokenWord && ignoreCase:
+		filter.Operator, filter.IgnoreCase = ignoreCaseOp, true
+	default:
+		return entity.UserQuery{}, p.unexpected("an operator")
+	}
+	err = p.advance()
+	if err != nil {
+		return entity.UserQuery{}, err
+	}
+
+	switch filter.Operator {
+	case entity.In, entity.NotIn:
+		filter.Value, err = p.list()
+	case entity.Exists:
+		filter.Value = true
+		if p.token.keyword("true") || p.token.keyword("false") {
+			filter.Value, err = p.value()
+		}
+	default:
+		filter.Value, err = p.value()
+	}
+	if err != nil {
+		return entity.UserQuery{}, err
+	}
+
+	err = filter.Validate()
+	if err != nil {
+		return entity.UserQuery{}, &SyntaxError{Pos: start, Msg: err.Error()}
+	}
+	return entity.UserQuery{Filter: &filter}, nil
+}
+
+func (p *parser) value() (interface{}, error) {
+	tok := p.token
+	var value interface{}
+	switch {
+	case tok.keyword("true"):
+		value = true
+	case tok.keyword("false"):
+		value = false
+	case tok.kind == tokenString || tok.kind == tokenNumber:
+		value = tok.value
+	case tok.kind == tokenWord && !tok.keyword("and") && !tok.keyword("or") && !tok.keyword("not"):
+		value = tok.text
+	default:
+		return nil, p.unexpected("a value")
+	}
+	return value, p.advance()
+}
+
+func (p *parser) list() ([]interface{}, error) {
+	if p.token.kind != tokenLBracket {
+		return nil, p.unexpected("[")
+	}
+	err := p.advance()
+	if err != nil {
+		return nil, err
+	}
+	values := []interface{}{}
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		if len(values) > entity.MaxQueryNodes {
+			return nil, &SyntaxError{Pos: p.token.pos, Msg: fmt.Sprintf("list has more than %d values", entity.MaxQueryNodes)}
+		}
+		switch p.token.kind {
+		case tokenComma:
+			err = p.advance()
+			if err != nil {
+				return nil, err
+			}
+		case tokenRBracket:
+			return values, p.advance()
+		default:
+			return nil, p.unexpected(", or ]")
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package dsl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func filter(field string, op entity.FilterOperation, value interface{}) entity.UserQuery {
+	return entity.UserQuery{Filter: &entity.UserFilter{Field: entity.UserFied(field), Operator: op, Value: value}}
+}
+
+func TestParse(t *testing.T) {
+	query, err := Parse(`age>=18 and email~"@corp.com"`)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.UserQuery{And: []entity.UserQuery{
+		filter("age", entity.GreaterOrEqual, 18.0),
+		filter("email", entity.Like, "@corp.com"),
+	}}, query)
+
+	query, err = Parse(`name = Luke or NOT (age < 30 and address exists) and name in ["Leia", "Han \"Solo\""]`)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.UserQuery{Or: []entity.UserQuery{
+		filter("name", entity.Equal, "Luke"),
+		{And: []entity.UserQuery{
+			{Not: &entity.UserQuery{And: []entity.UserQuery{
+				filter("age", entity.Less, 30.0),
+				filter("address", entity.Exists, true),
+			}}},
+			filter("name", entity.In, []interface{}{"Leia", `Han "Solo"`}),
+		}},
+	}}, query)
+
+	query, err = Parse(`address exists false or name prefix "Sky"`)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.UserQuery{Or: []entity.UserQuery{
+		filter("address", entity.Exists, false),
+		filter("name", entity.Prefix, "Sky"),
+	}}, query)
+
+	query, err = Parse(`email iEndsWith "@Corp.com" and name REGEX "^L"`)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.UserQuery{And: []entity.UserQuery{
+		{Filter: &entity.UserFilter{Field: "email", Operator: entity.EndsWith, Value: "@Corp.com", IgnoreCase: true}},
+		filter("name", entity.Regex, "^L"),
+	}}, query)
+
+	query, err = Parse("  ")
+	assert.Nil(t, err)
+	assert.True(t, query.IsEmpty())
+}
+
+func TestParseErrorPositions(t *testing.T) {
+	cases := map[string]int{
+		`age>=18 and`:               11,
+		`age>=18 email="a"`:         8,
+		`password="secret"`:         0,
+		`age ~ "1"`:                 0,
+		`name="Luke`:                5,
+		`(name=Luke`:                10,
+		`name in ["Luke" "Leia"]`:   16,
+		`age > 18.5.1`:              6,
+		`name = Luke and age ?? 18`: 20,
+		`name == Luke`:              6,
+		`name regex "(a+)+"`:        0,
+	}
+	for input, pos := range cases {
+		_, err := Parse(input)
+		if assert.IsType(t, &SyntaxError{}, err, input) {
+			assert.Equal(t, pos, err.(*SyntaxError).Pos, "%s: %v", input, err)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	_, err := Parse(strings.Repeat("not ", entity.MaxQueryDepth) + "name=Luke")
+	assert.NotNil(t, err)
+
+	_, err = Parse(strings.Repeat("name=Luke or ", entity.MaxQueryNodes) + "name=Luke")
+	assert.NotNil(t, err)
+
+	_, err = Parse(strings.Repeat(" ", MaxLength+1))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/injection"
+	"github.com/Shodocan/UserService/internal/web/requests"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	ActorHeader    = "X-Actor"
+	ClientIDHeader = "X-Client-ID"
+	defaultActor   = "api"
+	// PrivilegedLocal is set by the authentication for requests made with a privileged API token
+	PrivilegedLocal = "privileged"
+)
+
+// auditContext identifies the caller of a request, falling back to the user agent as client.
+// The headers are only descriptive, the privilege comes from the authenticated token
+func auditContext(ctx *fiber.Ctx) entity.AuditContext {
+	privileged, _ := ctx.Locals(PrivilegedLocal).(bool)
+	return entity.AuditContext{
+		Actor:      ctx.Get(ActorHeader, defaultActor),
+		Client:     ctx.Get(ClientIDHeader, ctx.Get(fiber.HeaderUserAgent)),
+		IP:         ctx.IP(),
+		Privileged: privileged,
+	}
+}
+
+// RequirePrivileged rejects the requests that did not authenticate with a privileged API token
+func RequirePrivileged(ctx *fiber.Ctx) error {
+	if privileged, _ := ctx.Locals(PrivilegedLocal).(bool); !privileged {
+		return engine.NewGenericError(http.StatusForbidden, "Requires a privileged API token")
+	}
+	return ctx.Next()
+}
+
+// UserAudit godoc
+// @Summary User Audit
+// @Description List the audit events of a user, newest first
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Param limit query int false "Page size" default(20)
+// @Param page query int false "Page" default(1)
+// @Success 200 {object} engine.PaginationResponse{data=[]entity.AuditEvent}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /users/{id}/audit [get]
+func UserAudit(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeAuditCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		if id == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		var request requests.PageRequest
+		err := ctx.QueryParser(&request)
+		if err != nil {
+			return engine.ErrBadRequest().Message(err.Error())
+		}
+		request = request.WithDefaults()
+
+		events, pagination, err := useCase.UserHistory(id, request.Limit, request.Page)
+		if err != nil {
+			return err
+		}
+
+		setLinks(ctx, pagination)
+		response := engine.NewResponsePaginated(events, pagination, "Audit Events Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// SearchAudit godoc
+// @Summary Search Audit
+// @Description Search the audit events of all users, newest first
+// @Accept  json
+// @Produce  json
+// @Param Request body requests.SearchAuditRequest true "Search Audit Request"
+// @Success 200 {object} engine.PaginationResponse{data=[]entity.AuditEvent}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /audit/search [post]
+func SearchAudit(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeAuditCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		var request requests.SearchAuditRequest
+		err := ctx.BodyParser(&request)
+		if err != nil {
+			return err
+		}
+
+		err = request.Validate()
+		if err != nil {
+			return err
+		}
+
+		events, pagination, err := useCase.Search(request.Filter(), request.Limit, request.Page)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponsePaginated(events, pagination, "Audit Events Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/injection"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CacheFlush is the result of flushing a namespace cache
+type CacheFlush struct {
+	Namespace string `json:"namespace"`
+	Deleted   int64  `json:"deleted"`
+}
+
+// FlushCache godoc
+// @Summary Flush Cache
+// @Description Drop every cached document and query of a namespace, on every instance
+// @Accept  json
+// @Produce  json
+// @Param namespace path string true "Namespace" example(users)
+// @Success 200 {object} engine.Response{data=handlers.CacheFlush}
+// @Failure 400,401,403,500,501 {object} engine.Error
+// @Router /admin/cache/{namespace} [delete]
+func FlushCache(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeCacheCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		namespace := ctx.Params("namespace")
+		deleted, err := useCase.Flush(namespace)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(CacheFlush{Namespace: namespace, Deleted: deleted}, "Cache Flushed")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// CacheStats godoc
+// @Summary Cache Stats
+// @Description Hits, misses, errors, latency and evictions of the cache, counted by the instance serving the request since it started
+// @Accept  json
+// @Produce  json
+// @Success 200 {object} engine.Response{data=entity.CacheStats}
+// @Failure 401,403,500,501 {object} engine.Error
+// @Router /admin/cache/stats [get]
+func CacheStats(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeCacheCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		stats, err := useCase.Stats()
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(stats, "Cache Stats")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// InspectUserCache godoc
+// @Summary Inspect User Cache
+// @Description Show what the cache holds for a user, the user is never loaded
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} engine.Response{data=entity.CacheEntry}
+// @Failure 400,401,403,500,501 {object} engine.Error
+// @Router /admin/cache/users/{id} [get]
+func InspectUserCache(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeCacheCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		if id == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		entry, err := useCase.InspectUser(id)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(entry, "Cache Entry")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// EvictUserCache godoc
+// @Summary Evict User Cache
+// @Description Drop the cached user, on every instance, and the cached queries of users
+// @Accept  json
+// @Produce  json
+// @Param id path string true "User ID"
+// @Success 200 {object} engine.Response{data=string}
+// @Failure 400,401,403,500,501 {object} engine.Error
+// @Router /admin/cache/users/{id} [delete]
+func EvictUserCache(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeCacheCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		if id == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		err := useCase.EvictUser(id)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK("", "Cache Entry Evicted")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
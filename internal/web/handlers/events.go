@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/injection"
+	"github.com/Shodocan/UserService/internal/web/sse"
+	"github.com/gofiber/fiber/v2"
+)
+
+const LastEventIDHeader = "Last-Event-ID"
+
+var (
+	streamsMutex sync.Mutex
+	streams      []*sse.Stream
+)
+
+// CloseStreams ends the open event streams, must run before the server shutdown
+// as it waits for every connection to finish
+func CloseStreams() {
+	streamsMutex.Lock()
+	defer streamsMutex.Unlock()
+	for _, stream := range streams {
+		stream.Close()
+	}
+}
+
+// parseEventTypes reads a comma separated list of event types
+func parseEventTypes(value string) ([]entity.UserEventType, error) {
+	types := []entity.UserEventType{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		eventType := entity.UserEventType(name)
+		if !eventType.Valid() {
+			return nil, engine.NewGenericError(http.StatusBadRequest, "Invalid Request").ExtraData(map[string]interface{}{"types": "unknown event type " + name})
+		}
+		types = append(types, eventType)
+	}
+	return types, nil
+}
+
+func toSSEEvents(events []entity.UserEvent) []sse.Event {
+	sseEvents := []sse.Event{}
+	for _, event := range events {
+		sseEvents = append(sseEvents, sse.Event{ID: event.ID, Type: string(event.Type), Data: event})
+	}
+	return sseEvents
+}
+
+// UserEvents godoc
+// @Summary User Events
+// @Description Server-Sent Events stream of user lifecycle events, send Last-Event-ID to resume after the last received event
+// @Produce  text/event-stream
+// @Param types query string false "Comma separated event types" example(user.created,user.deleted)
+// @Param Last-Event-ID header string false "ID of the last received event"
+// @Success 200 {object} entity.UserEvent
+// @Failure 400,401,500 {object} engine.Error
+// @Router /users/events [get]
+func UserEvents(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeEventCase(config, db)
+	logger := configs.NewLog()
+	stream := sse.NewStream(config, logger)
+	batchSize := sse.BatchSize(config, logger)
+	streamsMutex.Lock()
+	streams = append(streams, stream)
+	streamsMutex.Unlock()
+	return func(ctx *fiber.Ctx) error {
+		types, err := parseEventTypes(ctx.Query("types"))
+		if err != nil {
+			return err
+		}
+		lastEventID := ctx.Get(LastEventIDHeader, ctx.Query("lastEventId"))
+		since := time.Now().UTC()
+
+		// the first read happens before streaming so a bad Last-Event-ID is still a proper error
+		initial, err := useCase.Poll(lastEventID, since, types, batchSize)
+		if err != nil {
+			return err
+		}
+
+		ctx.Set(fiber.HeaderContentType, sse.ContentType)
+		ctx.Set(fiber.HeaderCacheControl, "no-cache")
+		ctx.Set(fiber.HeaderConnection, "keep-alive")
+		ctx.Set("X-Accel-Buffering", "no")
+		// the writer runs after the handler returns, it must not touch ctx
+		ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			stream.Run(w, lastEventID, toSSEEvents(initial), func(lastEventID string) ([]sse.Event, error) {
+				events, err := useCase.Poll(lastEventID, since, types, batchSize)
+				return toSSEEvents(events), err
+			})
+		})
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/gofiber/fiber/v2"
+)
+
+// setLinks writes the RFC 8288 Link header of a listing read from the query string. Pages read by
+// number link to the pages around them by number, pages read through a cursor link to their cursors
+func setLinks(ctx *fiber.Ctx, pagination engine.Pagination) {
+	requested, err := url.Parse(ctx.OriginalURL())
+	if err != nil {
+		return
+	}
+	to := func(param, value string) string {
+		query := requested.Query()
+		query.Del("page")
+		query.Del("cursor")
+		if param != "" {
+			query.Set(param, value)
+		}
+		target := *requested
+		target.RawQuery = query.Encode()
+		return target.String()
+	}
+
+	links := []string{}
+	if pagination.Page == 0 {
+		links = append(links, to("", ""), "first")
+		if pagination.Prev != "" {
+			links = append(links, to("cursor", pagination.Prev), "prev")
+		}
+		if pagination.Next != "" {
+			links = append(links, to("cursor", pagination.Next), "next")
+		}
+	} else {
+		links = append(links, to("page", "1"), "first")
+		if pagination.HasPrev {
+			links = append(links, to("page", strconv.Itoa(pagination.Page-1)), "prev")
+		}
+		if pagination.HasNext {
+			links = append(links, to("page", strconv.Itoa(pagination.Page+1)), "next")
+		}
+		if pagination.Counted() && pagination.Pages > 0 {
+			links = append(links, to("page", strconv.Itoa(pagination.Pages)), "last")
+		}
+	}
+	ctx.Links(links...)
+}
@@ -19,10 +19,11 @@ import (
 // @Produce  json
 // @Param Request body requests.SearchUserRequest true "Search Users Request"
 // @Success 200 {object} engine.PaginationResponse{data=[]entity.User}
-// @Failure 400,401,404,500 {object} engine.Error
+// @Failure 400,401,403,404,500 {object} engine.Error
 // @Router /users/search [post]
 func SearchUsers(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
 	useCase := injection.InitializeUserCase(config, db)
+	maxPageSize := requests.MaxPageSize(config, configs.NewLog())
 	return func(ctx *fiber.Ctx) error {
 		var request requests.SearchUserRequest
 		err := ctx.BodyParser(&request)
@@ -30,17 +31,152 @@ func SearchUsers(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handle
 			return err
 		}
 
-		err = request.Validate()
+		err = request.Validate(maxPageSize)
+		if err != nil {
+			return err
+		}
+
+		var users []entity.User
+		var pagination engine.Pagination
+		if cursor, ok := request.PageCursor(); ok {
+			users, pagination, err = useCase.As(auditContext(ctx)).Scroll(request.UserQuery(), request.SortRules(), cursor, request.Limit, request.SkipTotal, request.SelectedFields()...)
+		} else {
+			users, pagination, err = useCase.As(auditContext(ctx)).Search(request.UserQuery(), request.SortRules(), request.Limit, request.Page, request.SkipTotal, request.SelectedFields()...)
+		}
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponsePaginated(sparse(users, request.SelectedFields()), pagination, "Users Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// ListUsers godoc
+// @Summary List Users
+// @Description List users matching a filter expression, like age>=18 and (email~"@corp.com" or not address exists).
+// @Description Operators are =, !=, >, >=, <, <=, ~, prefix, contains, startsWith, endsWith, iequals, in ["a", "b"], nin [...] and exists [true|false], combined with and, or, not and parentheses.
+// @Description icontains, istartsWith and iendsWith ignore the case. regex takes a raw regular expression and requires a privileged API token
+// @Accept  json
+// @Produce  json
+// @Param q query string false "Filter expression"
+// @Param text query string false "Words searched in the name, email and address, sorted by score unless sorted otherwise"
+// @Param sort query string false "Sort fields, - for descending, score sorts a text search by relevance" default(-name,age)
+// @Param limit query int false "Page size" default(20)
+// @Param page query int false "Page" default(1)
+// @Param cursor query string false "The next or prev cursor of a previous page, replaces page"
+// @Param skipTotal query bool false "Skip counting the users, total and pages are -1"
+// @Param fields query string false "The only fields returned, of id, name, age, email and address" default(id,name,email)
+// @Success 200 {object} engine.PaginationResponse{data=[]entity.User}
+// @Header 200 {string} Link "The first, prev, next and last pages"
+// @Failure 400,401,403,404,500 {object} engine.Error
+// @Router /users [get]
+func ListUsers(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeUserCase(config, db)
+	maxPageSize := requests.MaxPageSize(config, configs.NewLog())
+	return func(ctx *fiber.Ctx) error {
+		var request requests.ListUsersRequest
+		err := ctx.QueryParser(&request)
+		if err != nil {
+			return engine.ErrBadRequest().Message(err.Error())
+		}
+
+		query, sort, page, err := request.Parse(maxPageSize)
+		if err != nil {
+			return err
+		}
+		cursor, scroll, err := request.PageCursor(sort)
+		if err != nil {
+			return err
+		}
+		fields, err := request.SelectedFields()
 		if err != nil {
 			return err
 		}
 
-		users, pagination, err := useCase.Search(request.Filters, request.Sort, request.Limit, request.Page)
+		var users []entity.User
+		var pagination engine.Pagination
+		if scroll {
+			users, pagination, err = useCase.As(auditContext(ctx)).Scroll(query, sort, cursor, page.Limit, request.SkipTotal, fields...)
+		} else {
+			users, pagination, err = useCase.As(auditContext(ctx)).Search(query, sort, page.Limit, page.Page, request.SkipTotal, fields...)
+		}
 		if err != nil {
 			return err
 		}
 
-		response := engine.NewResponsePaginated(users, pagination, "Users Found")
+		setLinks(ctx, pagination)
+		response := engine.NewResponsePaginated(sparse(users, fields), pagination, "Users Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// FuzzySearch godoc
+// @Summary Fuzzy Search Users
+// @Description Look users up by a misspelled name or email. Users are ranked by the similarity of their name or email to q, from 0 to 1 in score, the best of the trigram and the edit distance similarity
+// @Accept  json
+// @Produce  json
+// @Param q query string true "Name or email, misspelled or not"
+// @Param threshold query number false "The least similarity of the users found, from 0 to 1" default(0.3)
+// @Param limit query int false "The most users found" default(20)
+// @Success 200 {object} engine.Response{data=[]entity.User}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /users/search/fuzzy [get]
+func FuzzySearchUsers(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeUserCase(config, db)
+	limits := requests.FuzzyConfig(config, configs.NewLog())
+	return func(ctx *fiber.Ctx) error {
+		var request requests.FuzzySearchRequest
+		err := ctx.QueryParser(&request)
+		if err != nil {
+			return engine.ErrBadRequest().Message(err.Error())
+		}
+
+		search, err := request.Search(limits)
+		if err != nil {
+			return err
+		}
+
+		users, err := useCase.Fuzzy(search)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(users, "Users Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// Aggregate godoc
+// @Summary Aggregate Users
+// @Description Count the users matching the same filters, query and text as a search by groupBy: age brackets, the email domain, the city, the last comma separated part of the address, or the UTC day or month of creation.
+// @Description Age brackets start at each of buckets, users without a key are counted in unknown. Dates are listed in order, other groups the largest first, at most 100 of them
+// @Accept  json
+// @Produce  json
+// @Param Request body requests.AggregateUsersRequest true "Aggregate Users Request"
+// @Success 200 {object} engine.Response{data=[]entity.UserGroup}
+// @Failure 400,401,403,404,500 {object} engine.Error
+// @Router /users/aggregate [post]
+func AggregateUsers(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeUserCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		var request requests.AggregateUsersRequest
+		err := ctx.BodyParser(&request)
+		if err != nil {
+			return err
+		}
+
+		aggregation, err := request.Aggregation()
+		if err != nil {
+			return err
+		}
+
+		groups, err := useCase.As(auditContext(ctx)).Aggregate(aggregation)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(groups, "Users Aggregated")
 		return ctx.Status(http.StatusOK).JSON(response)
 	}
 }
@@ -51,6 +187,7 @@ func SearchUsers(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handle
 // @Accept  json
 // @Produce  json
 // @Param id path string true "User ID"
+// @Param fields query string false "The only fields returned, of id, name, age, email and address" default(id,name,email)
 // @Success 200 {object} engine.Response{data=entity.User}
 // @Failure 400,401,404,500 {object} engine.Error
 // @Router /users/{id} [get]
@@ -62,12 +199,21 @@ func FindUser(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
 			return engine.ErrBadRequest().Message("ID is required")
 		}
 
-		user, err := useCase.Find(id)
+		fields, err := requests.ParseFields(ctx.Query("fields"))
 		if err != nil {
 			return err
 		}
 
-		response := engine.NewResponseOK(user, "User Found")
+		user, err := useCase.Find(id, fields...)
+		if err != nil {
+			return err
+		}
+
+		var data interface{} = user
+		if len(fields) > 0 {
+			data = user.Select(fields)
+		}
+		response := engine.NewResponseOK(data, "User Found")
 		return ctx.Status(http.StatusOK).JSON(response)
 	}
 }
@@ -100,7 +246,7 @@ func ValidatePassword(config *configs.EnvVarConfig, db database.MongoDB) fiber.H
 			return engine.ErrBadRequest().Message("ID is required")
 		}
 
-		err = useCase.ValidatePassword(id, request.Password)
+		err = useCase.As(auditContext(ctx)).ValidatePassword(id, request.Password)
 		if err != nil {
 			return err
 		}
@@ -132,7 +278,7 @@ func CreateUser(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler
 			return err
 		}
 
-		user, err := useCase.Create(request)
+		user, err := useCase.As(auditContext(ctx)).Create(request)
 		if err != nil {
 			return err
 		}
@@ -171,7 +317,7 @@ func UpdateUser(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler
 			return err
 		}
 
-		user, err := useCase.Update(id, request)
+		user, err := useCase.As(auditContext(ctx)).Update(id, request)
 		if err != nil {
 			return err
 		}
@@ -206,7 +352,7 @@ func PartialUpdateUser(config *configs.EnvVarConfig, db database.MongoDB) fiber.
 			return engine.ErrBadRequest().Message("ID is required")
 		}
 
-		user, err := useCase.PartialUpdate(id, request)
+		user, err := useCase.As(auditContext(ctx)).PartialUpdate(id, request)
 		if err != nil {
 			return err
 		}
@@ -234,7 +380,7 @@ func Delete(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
 			return engine.ErrBadRequest().Message("ID is required")
 		}
 
-		err := useCase.Delete(id)
+		err := useCase.As(auditContext(ctx)).Delete(id)
 		if err != nil {
 			return err
 		}
@@ -244,3 +390,15 @@ func Delete(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
 		return ctx.Status(http.StatusOK).JSON(response)
 	}
 }
+
+// sparse renders the users with only the fields requested, whole when there is none
+func sparse(users []entity.User, fields []entity.UserFied) interface{} {
+	if len(fields) == 0 {
+		return users
+	}
+	selected := make([]map[string]interface{}, 0, len(users))
+	for _, user := range users {
+		selected = append(selected, user.Select(fields))
+	}
+	return selected
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/injection"
+	"github.com/Shodocan/UserService/internal/web/requests"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CreateWebhook godoc
+// @Summary Create Webhook
+// @Description Subscribe an URL to user events, payloads are signed with the returned secret
+// @Accept  json
+// @Produce  json
+// @Param Request body entity.WebhookSubscription true "Create Webhook Request"
+// @Success 201 {object} engine.Response{data=entity.WebhookSubscription}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks [post]
+func CreateWebhook(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		var request entity.WebhookSubscription
+		err := ctx.BodyParser(&request)
+		if err != nil {
+			return err
+		}
+
+		err = request.Validate()
+		if err != nil {
+			return err
+		}
+
+		subscription, err := useCase.Subscribe(request)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseCreated(subscription, "Webhook Created")
+		return ctx.Status(http.StatusCreated).JSON(response)
+	}
+}
+
+// ListWebhooks godoc
+// @Summary List Webhooks
+// @Description List webhook subscriptions
+// @Accept  json
+// @Produce  json
+// @Param limit query int false "Page size" default(20)
+// @Param page query int false "Page" default(1)
+// @Success 200 {object} engine.PaginationResponse{data=[]entity.WebhookSubscription}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks [get]
+func ListWebhooks(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		var request requests.PageRequest
+		err := ctx.QueryParser(&request)
+		if err != nil {
+			return engine.ErrBadRequest().Message(err.Error())
+		}
+		request = request.WithDefaults()
+
+		subscriptions, pagination, err := useCase.List(request.Limit, request.Page)
+		if err != nil {
+			return err
+		}
+
+		setLinks(ctx, pagination)
+		response := engine.NewResponsePaginated(subscriptions, pagination, "Webhooks Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// FindWebhook godoc
+// @Summary Find Webhook
+// @Description Find webhook subscription
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} engine.Response{data=entity.WebhookSubscription}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks/{id} [get]
+func FindWebhook(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		if id == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		subscription, err := useCase.Find(id)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(subscription, "Webhook Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// DeleteWebhook godoc
+// @Summary Delete Webhook
+// @Description Remove webhook subscription
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} engine.Response{data=string}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks/{id} [delete]
+func DeleteWebhook(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		if id == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		err := useCase.Unsubscribe(id)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK("", "Webhook Deleted")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// WebhookDeliveries godoc
+// @Summary Webhook Deliveries
+// @Description Delivery history of a webhook subscription, newest first
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "Page size" default(20)
+// @Param page query int false "Page" default(1)
+// @Success 200 {object} engine.PaginationResponse{data=[]entity.WebhookDelivery}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks/{id}/deliveries [get]
+func WebhookDeliveries(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		if id == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		var request requests.PageRequest
+		err := ctx.QueryParser(&request)
+		if err != nil {
+			return engine.ErrBadRequest().Message(err.Error())
+		}
+		request = request.WithDefaults()
+
+		deliveries, pagination, err := useCase.Deliveries(id, request.Limit, request.Page)
+		if err != nil {
+			return err
+		}
+
+		setLinks(ctx, pagination)
+		response := engine.NewResponsePaginated(deliveries, pagination, "Deliveries Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// WebhookDeadLetters godoc
+// @Summary Webhook Dead Letters
+// @Description Deliveries that exhausted their attempts, newest first
+// @Accept  json
+// @Produce  json
+// @Param limit query int false "Page size" default(20)
+// @Param page query int false "Page" default(1)
+// @Success 200 {object} engine.PaginationResponse{data=[]entity.WebhookDelivery}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks/dead-letters [get]
+func WebhookDeadLetters(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		var request requests.PageRequest
+		err := ctx.QueryParser(&request)
+		if err != nil {
+			return engine.ErrBadRequest().Message(err.Error())
+		}
+		request = request.WithDefaults()
+
+		deliveries, pagination, err := useCase.DeadLetters(request.Limit, request.Page)
+		if err != nil {
+			return err
+		}
+
+		setLinks(ctx, pagination)
+		response := engine.NewResponsePaginated(deliveries, pagination, "Dead Letters Found")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver Webhook
+// @Description Send a delivery again right away
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Webhook ID"
+// @Param deliveryId path string true "Delivery ID"
+// @Success 200 {object} engine.Response{data=entity.WebhookDelivery}
+// @Failure 400,401,404,500 {object} engine.Error
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhook(config *configs.EnvVarConfig, db database.MongoDB) fiber.Handler {
+	useCase := injection.InitializeWebhookCase(config, db)
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Params("id")
+		deliveryID := ctx.Params("deliveryId")
+		if id == "" || deliveryID == "" {
+			return engine.ErrBadRequest().Message("ID is required")
+		}
+
+		delivery, err := useCase.Redeliver(id, deliveryID)
+		if err != nil {
+			return err
+		}
+
+		response := engine.NewResponseOK(delivery, "Delivery Attempted")
+		return ctx.Status(http.StatusOK).JSON(response)
+	}
+}
@@ -0,0 +1,37 @@
+package requests
+
+import (
+	"net/http"
+
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// AggregateUsersRequest counts the users matching the filters, the query expression and the
+// text search, like a search, by groupBy. Buckets are the lower bounds of the age brackets
+// when grouping by age, the default ones when omitted
+type AggregateUsersRequest struct {
+	Text    string              `json:"text,omitempty" example:"skywalker"`
+	Filters []entity.UserFilter `json:"filters,omitempty"`
+	Query   *entity.UserQuery   `json:"query,omitempty"`
+	GroupBy string              `json:"groupBy" example:"age" enums:"age,emailDomain,city,createdDay,createdMonth"`
+	Buckets []int               `json:"buckets,omitempty" example:"0,18,30,50"`
+}
+
+// Aggregation validates the request
+func (r AggregateUsersRequest) Aggregation() (entity.UserAggregation, error) {
+	validationErrors := queryErrors(r.Filters, r.Query, r.Text)
+	grouping, err := entity.ParseGrouping(r.GroupBy)
+	if err != nil {
+		validationErrors["groupBy"] = err.Error()
+	}
+	if len(r.Buckets) > 0 && grouping != entity.GroupByAge {
+		validationErrors["buckets"] = "buckets only apply to the age"
+	} else if err := entity.CheckBuckets(r.Buckets); err != nil {
+		validationErrors["buckets"] = err.Error()
+	}
+	if len(validationErrors) > 0 {
+		return entity.UserAggregation{}, engine.NewGenericError(http.StatusBadRequest, "Invalid Request").ExtraData(validationErrors)
+	}
+	return entity.UserAggregation{Query: userQuery(r.Filters, r.Query, r.Text), GroupBy: grouping, Buckets: r.Buckets}, nil
+}
@@ -0,0 +1,32 @@
+package requests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateUsersRequest(t *testing.T) {
+	filter := entity.UserFilter{Field: "age", Operator: entity.Greater, Value: 18.0}
+	aggregation, err := AggregateUsersRequest{Text: "skywalker", Filters: []entity.UserFilter{filter}, GroupBy: "age", Buckets: []int{0, 30}}.Aggregation()
+	assert.Nil(t, err)
+	assert.Equal(t, entity.GroupByAge, aggregation.GroupBy)
+	assert.Equal(t, []int{0, 30}, aggregation.Buckets)
+	assert.Equal(t, "skywalker", aggregation.Query.Text)
+	assert.Equal(t, &filter, aggregation.Query.And[0].Filter)
+
+	_, err = AggregateUsersRequest{GroupBy: "password", Text: strings.Repeat("a", entity.MaxTextLength+1)}.Aggregation()
+	assert.Len(t, err.(*engine.Error).Extra, 2)
+
+	_, err = AggregateUsersRequest{GroupBy: "city", Buckets: []int{0, 30}}.Aggregation()
+	assert.Contains(t, err.(*engine.Error).Extra, "buckets", "buckets only apply to the age")
+	_, err = AggregateUsersRequest{GroupBy: "age", Buckets: []int{30, 0}}.Aggregation()
+	assert.Contains(t, err.(*engine.Error).Extra, "buckets")
+	_, err = AggregateUsersRequest{GroupBy: "age", Buckets: []int{0, 2147483647}}.Aggregation()
+	assert.Equal(t, http.StatusBadRequest, err.(*engine.Error).Code, "mongo rejects the boundaries past the last one")
+	assert.Contains(t, err.(*engine.Error).Extra, "buckets")
+}
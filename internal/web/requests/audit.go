@@ -0,0 +1,53 @@
+package requests
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+type SearchAuditRequest struct {
+	UserID string             `json:"userId,omitempty"`
+	Actor  string             `json:"actor,omitempty"`
+	Action entity.AuditAction `json:"action,omitempty" enums:"create,update,partial_update,delete,password_validation,password_change"`
+	From   *time.Time         `json:"from,omitempty"`
+	To     *time.Time         `json:"to,omitempty"`
+	Limit  int                `json:"limit" example:"10"`
+	Page   int                `json:"page" example:"1"`
+}
+
+func (r SearchAuditRequest) Validate() error {
+	validationErrors := map[string]interface{}{}
+	switch r.Action {
+	case "", entity.AuditCreate, entity.AuditUpdate, entity.AuditPartialUpdate, entity.AuditDelete,
+		entity.AuditPasswordValidation, entity.AuditPasswordChange:
+		break
+	default:
+		validationErrors["action"] = "Invalid action"
+	}
+	if r.From != nil && r.To != nil && r.From.After(*r.To) {
+		validationErrors["from"] = "from must be before to"
+	}
+	if r.Limit == 0 {
+		validationErrors["limit"] = "limit is required"
+	}
+	if r.Page == 0 {
+		validationErrors["page"] = "page is required"
+	}
+	if len(validationErrors) > 0 {
+		return engine.NewGenericError(http.StatusBadRequest, "Invalid Request").ExtraData(validationErrors)
+	}
+	return nil
+}
+
+func (r SearchAuditRequest) Filter() entity.AuditFilter {
+	return entity.AuditFilter{
+		UserID: r.UserID,
+		Actor:  r.Actor,
+		Action: r.Action,
+		From:   r.From,
+		To:     r.To,
+	}
+}
@@ -0,0 +1,25 @@
+package requests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchAuditValidation(t *testing.T) {
+	req := SearchAuditRequest{}
+	assert.NotNil(t, req.Validate(), "must not be a valid request")
+	req.Limit = 10
+	req.Page = 1
+	assert.Nil(t, req.Validate(), "must be a valid request")
+	req.Action = "rename"
+	assert.NotNil(t, req.Validate(), "must not accept unknown actions")
+	req.Action = "password_change"
+	assert.Nil(t, req.Validate(), "must be a valid request")
+
+	from := time.Now()
+	to := from.Add(-time.Hour)
+	req.From, req.To = &from, &to
+	assert.NotNil(t, req.Validate(), "must not accept inverted periods")
+}
@@ -0,0 +1,75 @@
+package requests
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+)
+
+// FuzzySearchRequest is the query string of the fuzzy user lookup, a zero threshold
+// or limit takes the configured one
+type FuzzySearchRequest struct {
+	Q         string  `query:"q" example:"luke skywalkr"`
+	Threshold float64 `query:"threshold" example:"0.3"`
+	Limit     int     `query:"limit" example:"10"`
+}
+
+// FuzzyLimits are the configured threshold, largest limit and number of candidates of the fuzzy search
+type FuzzyLimits struct {
+	Threshold  float64
+	MaxResults int
+	Candidates int
+}
+
+// FuzzyConfig reads the limits of the fuzzy search
+func FuzzyConfig(config *configs.EnvVarConfig, logger *log.Logger) FuzzyLimits {
+	threshold, err := strconv.ParseFloat(config.FuzzyThreshold, 64)
+	if err != nil || threshold < 0 || threshold > 1 {
+		logger.Printf("Invalid fuzzy threshold %s, using 0.3", config.FuzzyThreshold)
+		threshold = 0.3
+	}
+	maxResults, err := strconv.Atoi(config.FuzzyMaxResults)
+	if err != nil || maxResults <= 0 {
+		logger.Printf("Invalid fuzzy max results %s, using 20", config.FuzzyMaxResults)
+		maxResults = 20
+	}
+	candidates, err := strconv.Atoi(config.FuzzyCandidates)
+	if err != nil || candidates <= 0 {
+		logger.Printf("Invalid fuzzy candidates %s, using 500", config.FuzzyCandidates)
+		candidates = 500
+	}
+	return FuzzyLimits{Threshold: threshold, MaxResults: maxResults, Candidates: candidates}
+}
+
+// Search validates the request and fills what it omits from the limits
+func (r FuzzySearchRequest) Search(limits FuzzyLimits) (entity.FuzzySearch, error) {
+	validationErrors := map[string]interface{}{}
+	if r.Q == "" {
+		validationErrors["q"] = "q is required"
+	} else if len(r.Q) > entity.MaxFuzzyLength {
+		validationErrors["q"] = fmt.Sprintf("q has %d characters, at most %d are allowed", len(r.Q), entity.MaxFuzzyLength)
+	}
+	if r.Threshold < 0 || r.Threshold > 1 {
+		validationErrors["threshold"] = "threshold must be between 0 and 1"
+	}
+	if message, ok := checkLimit(r.Limit, limits.MaxResults); !ok {
+		validationErrors["limit"] = message
+	}
+	if len(validationErrors) > 0 {
+		return entity.FuzzySearch{}, engine.NewGenericError(http.StatusBadRequest, "Invalid Request").ExtraData(validationErrors)
+	}
+
+	search := entity.FuzzySearch{Text: r.Q, Threshold: r.Threshold, Limit: r.Limit, Candidates: limits.Candidates}
+	if search.Threshold == 0 {
+		search.Threshold = limits.Threshold
+	}
+	if search.Limit == 0 {
+		search.Limit = limits.MaxResults
+	}
+	return search, nil
+}
@@ -0,0 +1,31 @@
+package requests
+
+import (
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/configs/engine"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzySearchRequest(t *testing.T) {
+	limits := FuzzyLimits{Threshold: 0.3, MaxResults: 20, Candidates: 500}
+
+	search, err := FuzzySearchRequest{Q: "skywalkr"}.Search(limits)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.FuzzySearch{Text: "skywalkr", Threshold: 0.3, Limit: 20, Candidates: 500}, search)
+
+	search, err = FuzzySearchRequest{Q: "skywalkr", Threshold: 0.6, Limit: 5}.Search(limits)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.6, search.Threshold)
+	assert.Equal(t, 5, search.Limit)
+
+	_, err = FuzzySearchRequest{Threshold: 2, Limit: 21}.Search(limits)
+	assert.Len(t, err.(*engine.Error).Extra, 3)
+}
+
+func TestFuzzyConfig(t *testing.T) {
+	limits := FuzzyConfig(&configs.EnvVarConfig{FuzzyThreshold: "0.5", FuzzyMaxResults: "x", FuzzyCandidates: "100"}, configs.NewLog())
+	assert.Equal(t, FuzzyLimits{Threshold: 0.5, MaxResults: 20, Candidates: 100}, limits)
+}
@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Shodocan/UserService/internal/configs"
+)
+
+const defaultPageLimit = 20
+
+// PageRequest is the pagination of the listings read from the query string
+type PageRequest struct {
+	Limit int `query:"limit" example:"20"`
+	Page  int `query:"page" example:"1"`
+}
+
+// WithDefaults fills the pagination omitted from the query string
+func (r PageRequest) WithDefaults() PageRequest {
+	if r.Limit <= 0 {
+		r.Limit = defaultPageLimit
+	}
+	if r.Page <= 0 {
+		r.Page = 1
+	}
+	return r
+}
+
+// MaxPageSize reads the largest limit a listing accepts
+func MaxPageSize(config *configs.EnvVarConfig, logger *log.Logger) int {
+	maxPageSize, err := strconv.Atoi(config.MaxPageSize)
+	if err != nil || maxPageSize <= 0 {
+		logger.Printf("Invalid max page size %s, using 100", config.MaxPageSize)
+		maxPageSize = 100
+	}
+	return maxPageSize
+}
+
+// checkLimit explains why a limit is out of the accepted range
+func checkLimit(limit, maxLimit int) (string, bool) {
+	if limit < 0 || limit > maxLimit {
+		return fmt.Sprintf("limit must be between 1 and %d", maxLimit), false
+	}
+	return "", true
+}
@@ -0,0 +1,22 @@
+package requests
+
+import (
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageDefaults(t *testing.T) {
+	req := PageRequest{}.WithDefaults()
+	assert.Equal(t, 1, req.Page)
+	assert.Equal(t, defaultPageLimit, req.Limit)
+	req = PageRequest{Page: 3, Limit: 5}.WithDefaults()
+	assert.Equal(t, 3, req.Page)
+	assert.Equal(t, 5, req.Limit)
+}
+
+func TestMaxPageSize(t *testing.T) {
+	assert.Equal(t, 50, MaxPageSize(&configs.EnvVarConfig{MaxPageSize: "50"}, configs.NewLog()))
+	assert.Equal(t, 100, MaxPageSize(&configs.EnvVarConfig{MaxPageSize: "-1"}, configs.NewLog()))
+}
@@ -3,35 +3,58 @@ package requests
 import (
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/Shodocan/UserService/internal/configs/engine"
 	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/web/dsl"
 )
 
 type ValidatePassword struct {
 	Password string `json:"password"`
 }
 
+// SearchUserRequest matches the users passing every filter and the query expression.
+// Cursor, the next or prev of a previous page, replaces the page number and SkipTotal
+// leaves the users uncounted, which is cheaper for expensive queries. Fields lists the
+// only fields returned. Text searches the words in the name, email and address, the
+// users are then sorted by score, their relevance, unless sorted otherwise
 type SearchUserRequest struct {
-	Filters []entity.UserFilter `json:"filters,omitempty"`
-	Sort    []string            `json:"sort,omitempty" example:"-name,age"`
-	Limit   int                 `json:"limit" example:"10"`
-	Page    int                 `json:"page" example:"1"`
+	Text      string              `json:"text,omitempty" example:"skywalker"`
+	Filters   []entity.UserFilter `json:"filters,omitempty"`
+	Query     *entity.UserQuery   `json:"query,omitempty"`
+	Sort      []string            `json:"sort,omitempty" example:"-name,age"`
+	Limit     int                 `json:"limit" example:"10"`
+	Page      int                 `json:"page" example:"1"`
+	Cursor    string              `json:"cursor,omitempty"`
+	SkipTotal bool                `json:"skipTotal,omitempty"`
+	Fields    []string            `json:"fields,omitempty" example:"id,name,email"`
 }
 
-func (u SearchUserRequest) Validate() error {
-	validationErrors := map[string]interface{}{}
-	if len(u.Filters) > 0 {
-		for i, filter := range u.Filters {
-			if !filter.Valid() {
-				validationErrors[fmt.Sprintf("filter%d", i)] = fmt.Sprintf("Invalid Filter: %s %s %s", filter.Field, filter.Operator, filter.Value)
-			}
+// Validate checks the request, the limit can't be over maxLimit
+func (u SearchUserRequest) Validate(maxLimit int) error {
+	validationErrors := queryErrors(u.Filters, u.Query, u.Text)
+	sort, sortErrors := entity.ParseSort(u.Sort)
+	for key, message := range sortErrors {
+		validationErrors[key] = message
+	}
+	if u.Text == "" && entity.SortsByScore(sort) {
+		validationErrors["sort"] = "score sorts the results of a text search"
+	}
+	if u.Cursor != "" && len(sortErrors) == 0 {
+		if _, err := entity.DecodeCursor(u.Cursor, sort); err != nil {
+			validationErrors["cursor"] = err.Error()
 		}
 	}
+	if _, err := entity.ParseFields(u.Fields); err != nil {
+		validationErrors["fields"] = err.Error()
+	}
 	if u.Limit == 0 {
 		validationErrors["lmit"] = "limit is required"
+	} else if message, ok := checkLimit(u.Limit, maxLimit); !ok {
+		validationErrors["lmit"] = message
 	}
-	if u.Page == 0 {
+	if u.Page == 0 && u.Cursor == "" {
 		validationErrors["page"] = "page is required"
 	}
 	if len(validationErrors) > 0 {
@@ -39,3 +62,150 @@ func (u SearchUserRequest) Validate() error {
 	}
 	return nil
 }
+
+// SortRules parses the sort, it must be validated first
+func (u SearchUserRequest) SortRules() []entity.SortRule {
+	rules, _ := entity.ParseSort(u.Sort)
+	return rules
+}
+
+// SelectedFields parses the fields, it must be validated first
+func (u SearchUserRequest) SelectedFields() []entity.UserFied {
+	fields, _ := entity.ParseFields(u.Fields)
+	return fields
+}
+
+// PageCursor decodes the cursor, false when the request is paginated by page number.
+// It must be validated first
+func (u SearchUserRequest) PageCursor() (entity.Cursor, bool) {
+	if u.Cursor == "" {
+		return entity.Cursor{}, false
+	}
+	cursor, _ := entity.DecodeCursor(u.Cursor, u.SortRules())
+	return cursor, true
+}
+
+// UserQuery combines the filters, the query expression and the text search
+func (u SearchUserRequest) UserQuery() entity.UserQuery {
+	return userQuery(u.Filters, u.Query, u.Text)
+}
+
+// queryErrors validates the filters, the query expression and the text search of a request
+func queryErrors(filters []entity.UserFilter, query *entity.UserQuery, text string) map[string]interface{} {
+	validationErrors := map[string]interface{}{}
+	for i, filter := range filters {
+		err := filter.Validate()
+		if err != nil {
+			validationErrors[fmt.Sprintf("filter%d", i)] = fmt.Sprintf("Invalid Filter: %v", err)
+		}
+	}
+	if query != nil {
+		for path, message := range query.Errors("query") {
+			validationErrors[path] = message
+		}
+	}
+	if err := entity.CheckText(text); err != nil {
+		validationErrors["text"] = err.Error()
+	}
+	return validationErrors
+}
+
+func userQuery(filters []entity.UserFilter, expression *entity.UserQuery, text string) entity.UserQuery {
+	query := entity.AllOf(filters...)
+	switch {
+	case expression == nil || expression.IsEmpty():
+	case query.IsEmpty():
+		query = *expression
+	default:
+		query.And = append(query.And, *expression)
+	}
+	query.Text = text
+	return query
+}
+
+// ListUsersRequest is the query string of the user listing, q is a filter expression
+// of the dsl package, text a text search and sort a comma separated list of fields
+type ListUsersRequest struct {
+	Q         string `query:"q" example:"age>=18 and email~\"@corp.com\""`
+	Text      string `query:"text" example:"skywalker"`
+	Sort      string `query:"sort" example:"-name,age"`
+	Limit     int    `query:"limit" example:"20"`
+	Page      int    `query:"page" example:"1"`
+	Cursor    string `query:"cursor"`
+	SkipTotal bool   `query:"skipTotal"`
+	Fields    string `query:"fields" example:"id,name,email"`
+}
+
+// Parse returns the query, the sort rules and the pagination, syntax errors carry their position
+func (r ListUsersRequest) Parse(maxLimit int) (entity.UserQuery, []entity.SortRule, PageRequest, error) {
+	page := PageRequest{Limit: r.Limit, Page: r.Page}.WithDefaults()
+	if message, ok := checkLimit(r.Limit, maxLimit); !ok {
+		return entity.UserQuery{}, nil, page, engine.NewGenericError(http.StatusBadRequest, "Invalid Request").ExtraData(map[string]interface{}{
+			"limit": message,
+		})
+	}
+	sort, sortErrors := entity.ParseSort(splitList(r.Sort))
+	if r.Text == "" && entity.SortsByScore(sort) {
+		sortErrors["sort"] = "score sorts the results of a text search"
+	}
+	if len(sortErrors) > 0 {
+		return entity.UserQuery{}, sort, page, engine.NewGenericError(http.StatusBadRequest, "Invalid Sort").ExtraData(sortErrors)
+	}
+	if err := entity.CheckText(r.Text); err != nil {
+		return entity.UserQuery{}, sort, page, engine.NewGenericError(http.StatusBadRequest, "Invalid Request").ExtraData(map[string]interface{}{
+			"text": err.Error(),
+		})
+	}
+
+	query, err := dsl.Parse(r.Q)
+	if syntaxErr, ok := err.(*dsl.SyntaxError); ok {
+		return query, sort, page, engine.NewGenericError(http.StatusBadRequest, "Invalid Query").ExtraData(map[string]interface{}{
+			"q":        syntaxErr.Msg,
+			"position": syntaxErr.Pos,
+		})
+	}
+	query.Text = r.Text
+	return query, sort, page, err
+}
+
+// PageCursor decodes the cursor for the parsed sort, false when the listing is paginated by page number
+func (r ListUsersRequest) PageCursor(sort []entity.SortRule) (entity.Cursor, bool, error) {
+	if r.Cursor == "" {
+		return entity.Cursor{}, false, nil
+	}
+	cursor, err := entity.DecodeCursor(r.Cursor, sort)
+	if err != nil {
+		return cursor, true, engine.NewGenericError(http.StatusBadRequest, "Invalid Cursor").ExtraData(map[string]interface{}{
+			"cursor": err.Error(),
+		})
+	}
+	return cursor, true, nil
+}
+
+// SelectedFields parses the fields, a comma separated list
+func (r ListUsersRequest) SelectedFields() ([]entity.UserFied, error) {
+	return ParseFields(r.Fields)
+}
+
+// ParseFields parses a comma separated list of user fields, like id,name,email
+func ParseFields(list string) ([]entity.UserFied, error) {
+	fields, err := entity.ParseFields(splitList(list))
+	if err != nil {
+		return nil, engine.NewGenericError(http.StatusBadRequest, "Invalid Fields").ExtraData(map[string]interface{}{
+			"fields": err.Error(),
+		})
+	}
+	return fields, nil
+}
+
+// splitList splits a comma separated list of the query string, ignoring blank items
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
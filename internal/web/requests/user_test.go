@@ -1,8 +1,11 @@
 package requests
 
 import (
+	"net/http"
+	"strings"
 	"testing"
 
+	"github.com/Shodocan/UserService/internal/configs/engine"
 	"github.com/Shodocan/UserService/internal/domain/entity"
 	"github.com/stretchr/testify/assert"
 )
@@ -10,19 +13,123 @@ import (
 func TestUserValidation(t *testing.T) {
 	req := SearchUserRequest{}
 
-	assert.NotNil(t, req.Validate(), "must not be a valid request")
+	assert.NotNil(t, req.Validate(100), "must not be a valid request")
 	req.Limit = 10
-	assert.NotNil(t, req.Validate(), "must still not be a valid request")
+	assert.NotNil(t, req.Validate(100), "must still not be a valid request")
 	req.Page = 1
-	assert.Nil(t, req.Validate(), "must be a valid request")
+	assert.Nil(t, req.Validate(100), "must be a valid request")
+	req.Fields = []string{"name", "password"}
+	assert.Contains(t, req.Validate(100).(*engine.Error).Extra, "fields", "the password can't be read")
+	req.Fields = []string{"id", "name"}
+	assert.Nil(t, req.Validate(100))
+	assert.Equal(t, []entity.UserFied{entity.ID, entity.Name}, req.SelectedFields())
+	req.Limit = 101
+	assert.Contains(t, req.Validate(100).(*engine.Error).Extra, "lmit", "the limit can't be over the max page size")
+	req.Limit = 10
 	req.Sort = []string{"name", "asdf"}
-	assert.Nil(t, req.Validate(), "must be a valid request")
+	assert.NotNil(t, req.Validate(100), "must not sort by unknown fields")
+	req.Sort = []string{"-name", "age"}
+	assert.Nil(t, req.Validate(100), "must be a valid request")
 	req.Filters = []entity.UserFilter{{Field: "name", Operator: "=", Value: "123"}}
-	assert.Nil(t, req.Validate(), "must be a valid request")
+	assert.Nil(t, req.Validate(100), "must be a valid request")
+	req.Filters = []entity.UserFilter{{Field: "age", Operator: ">=", Value: 12.0}, {Field: "age", Operator: "<", Value: "30"}}
+	assert.Nil(t, req.Validate(100), "must be a valid request")
 	req.Filters = []entity.UserFilter{{Field: "age", Operator: "~", Value: "12"}}
-	assert.Nil(t, req.Validate(), "must be a valid request")
+	assert.NotNil(t, req.Validate(100), "patterns must not be valid on numbers")
+	req.Filters = []entity.UserFilter{{Field: "email", Operator: "in", Value: []interface{}{"a@b.com", 12.0}}}
+	assert.NotNil(t, req.Validate(100), "list values must have the field type")
 	req.Filters = []entity.UserFilter{{Field: "name", Operator: "*", Value: "123"}}
-	assert.NotNil(t, req.Validate(), "must not be a valid request")
+	assert.NotNil(t, req.Validate(100), "must not be a valid request")
 	req.Filters = []entity.UserFilter{{Field: "fest", Operator: "=", Value: "123"}}
-	assert.NotNil(t, req.Validate(), "must not be a valid request")
+	assert.NotNil(t, req.Validate(100), "must not be a valid request")
+}
+
+func TestSearchUserQuery(t *testing.T) {
+	name := entity.UserFilter{Field: "name", Operator: "prefix", Value: "Luke"}
+	req := SearchUserRequest{Limit: 10, Page: 1, Filters: []entity.UserFilter{name}}
+	req.Query = &entity.UserQuery{Or: []entity.UserQuery{{Filter: &entity.UserFilter{Field: "age", Operator: "~", Value: "1"}}}}
+
+	err := req.Validate(100)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.(*engine.Error).Extra, "query.or[0].filter")
+
+	req.Query.Or[0].Filter.Operator = ">"
+	assert.Nil(t, req.Validate(100))
+	query := req.UserQuery()
+	assert.Len(t, query.And, 2, "filters and the query must both match")
+	assert.Equal(t, &name, query.And[0].Filter)
+}
+
+func TestSearchUserCursor(t *testing.T) {
+	sort := []entity.SortRule{{Field: entity.Age, Descending: true}}
+	req := SearchUserRequest{Limit: 10, Sort: []string{"-age"}, Cursor: "garbage"}
+	assert.Contains(t, req.Validate(100).(*engine.Error).Extra, "cursor")
+
+	next := entity.NewCursor(entity.User{ID: "5f1d7f9c7d1e2a0001a1b2c3", Age: 24}, sort, false)
+	req.Cursor = next.Encode()
+	assert.Nil(t, req.Validate(100), "the cursor replaces the page")
+	cursor, ok := req.PageCursor()
+	assert.True(t, ok)
+	assert.Equal(t, next, cursor)
+}
+
+func TestSearchUserText(t *testing.T) {
+	req := SearchUserRequest{Limit: 10, Page: 1, Sort: []string{"score"}}
+	assert.Contains(t, req.Validate(100).(*engine.Error).Extra, "sort", "only text searches have a score")
+
+	req.Text = "skywalker"
+	assert.Nil(t, req.Validate(100))
+	req.Filters = []entity.UserFilter{{Field: "age", Operator: ">", Value: 18.0}}
+	query := req.UserQuery()
+	assert.Equal(t, "skywalker", query.Text)
+	assert.Len(t, query.And, 1)
+
+	req.Text = strings.Repeat("a", entity.MaxTextLength+1)
+	assert.Contains(t, req.Validate(100).(*engine.Error).Extra, "text")
+
+	listing, _, _, err := ListUsersRequest{Text: "luke", Sort: "-score", Q: "age>18"}.Parse(100)
+	assert.Nil(t, err)
+	assert.Equal(t, "luke", listing.Text)
+	_, _, _, err = ListUsersRequest{Sort: "score"}.Parse(100)
+	assert.NotNil(t, err)
+}
+
+func TestListUsersRequestParse(t *testing.T) {
+	query, sort, page, err := ListUsersRequest{Q: `age>=18`, Sort: "-name, age,"}.Parse(100)
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.SortRule{{Field: entity.Name, Descending: true}, {Field: entity.Age}}, sort)
+	assert.Equal(t, PageRequest{Limit: defaultPageLimit, Page: 1}, page)
+	assert.Equal(t, &entity.UserFilter{Field: "age", Operator: ">=", Value: 18.0}, query.Filter)
+
+	next := entity.NewCursor(entity.User{ID: "5f1d7f9c7d1e2a0001a1b2c3", Name: "Luke", Age: 24}, sort, false)
+	cursor, scroll, err := ListUsersRequest{Cursor: next.Encode()}.PageCursor(sort)
+	assert.Nil(t, err)
+	assert.True(t, scroll)
+	assert.Equal(t, next, cursor)
+	_, _, err = ListUsersRequest{Cursor: next.Encode()}.PageCursor(nil)
+	assert.Equal(t, http.StatusBadRequest, err.(*engine.Error).Code, "cursors are bound to their sort")
+	_, scroll, _ = ListUsersRequest{}.PageCursor(sort)
+	assert.False(t, scroll)
+
+	_, _, _, err = ListUsersRequest{Limit: 500}.Parse(100)
+	assert.Equal(t, map[string]interface{}{"limit": "limit must be between 1 and 100"}, err.(*engine.Error).Extra)
+
+	_, _, _, err = ListUsersRequest{Sort: "name,password"}.Parse(100)
+	assert.Equal(t, map[string]interface{}{"sort1": `invalid sort field "password", use name, age, email, address or score`}, err.(*engine.Error).Extra)
+
+	_, _, _, err = ListUsersRequest{Q: `age>=18 and`}.Parse(100)
+	assert.Equal(t, map[string]interface{}{"q": "unexpected end of query, expected a field, not or (", "position": 11}, err.(*engine.Error).Extra)
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields(" id, email,")
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.UserFied{entity.ID, entity.Email}, fields)
+
+	fields, err = ParseFields("")
+	assert.Nil(t, err)
+	assert.Empty(t, fields, "every field is returned without a list")
+
+	_, err = ListUsersRequest{Fields: "password"}.SelectedFields()
+	assert.Equal(t, http.StatusBadRequest, err.(*engine.Error).Code)
 }
@@ -1,11 +1,14 @@
 package web
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strings"
 
 	_ "github.com/Shodocan/UserService/docs"
 	"github.com/Shodocan/UserService/internal/configs"
 	"github.com/Shodocan/UserService/internal/database"
+	"github.com/Shodocan/UserService/internal/metrics"
 	"github.com/Shodocan/UserService/internal/web/errors"
 	"github.com/Shodocan/UserService/internal/web/handlers"
 	swagger "github.com/arsmn/fiber-swagger/v2"
@@ -20,14 +23,30 @@ func Router(config *configs.EnvVarConfig, db database.MongoDB) *fiber.App {
 		fiber.Config{ErrorHandler: errors.ErrorHandler},
 	)
 
-	// simple endpoint used to check on the health status of the app
+	// simple endpoint used to check on the health status of the app,
+	// verbose=true adds the state of the database dependencies
 	srv.Get("/_healthz", func(ctx *fiber.Ctx) error {
+		status, message := http.StatusOK, "OK"
 		err := db.Ping()
 		if err != nil {
-			return ctx.Status(http.StatusInternalServerError).SendString("not healthy")
-		} else {
-			return ctx.Status(http.StatusOK).SendString("OK")
+			status, message = http.StatusInternalServerError, "not healthy"
 		}
+		if ctx.Query("verbose") != "true" {
+			return ctx.Status(status).SendString(message)
+		}
+		details := map[string]string{"status": message}
+		if reporter, ok := db.(database.HealthReporter); ok {
+			for name, state := range reporter.Health() {
+				details[name] = state
+			}
+		}
+		return ctx.Status(status).JSON(details)
+	})
+
+	// metrics in the Prometheus text format
+	srv.Get("/metrics", func(ctx *fiber.Ctx) error {
+		ctx.Set(fiber.HeaderContentType, "text/plain; version=0.0.4")
+		return metrics.Default.Write(ctx)
 	})
 
 	srv.Get("/swagger/*", swagger.Handler) // default
@@ -42,13 +61,21 @@ func Router(config *configs.EnvVarConfig, db database.MongoDB) *fiber.App {
 	apiGroup.Use(cors.New(cors.Config{
 		AllowOrigins:  config.AllowOrigins,
 		AllowMethods:  "PUT,GET,DELETE,POST",
-		AllowHeaders:  "Content-type,Authorization",
+		AllowHeaders:  "Content-type,Authorization," + handlers.ActorHeader + "," + handlers.ClientIDHeader + "," + handlers.LastEventIDHeader,
 		ExposeHeaders: "Content-Length,Content-type",
 		MaxAge:        36000,
 	}))
 
+	privilegedTokens := splitTokens(config.PrivilegedAPITokens)
 	apiGroup.Use(keyauth.New(keyauth.Config{
 		Validator: func(c *fiber.Ctx, s string) (bool, error) {
+			// privileged tokens authenticate too and unlock the raw regex filters
+			for _, token := range privilegedTokens {
+				if subtle.ConstantTimeCompare([]byte(s), []byte(token)) == 1 {
+					c.Locals(handlers.PrivilegedLocal, true)
+					return true, nil
+				}
+			}
 			return s == config.APIToken, nil
 		},
 	}))
@@ -62,9 +89,45 @@ func apiRouteGroup(api fiber.Router, config *configs.EnvVarConfig, db database.M
 	users := api.Group("/users")
 	users.Post("/password/:id", handlers.ValidatePassword(config, db))
 	users.Post("/search", handlers.SearchUsers(config, db))
+	users.Get("/search/fuzzy", handlers.FuzzySearchUsers(config, db))
+	users.Post("/aggregate", handlers.AggregateUsers(config, db))
+	users.Get("/", handlers.ListUsers(config, db))
+	users.Get("/events", handlers.UserEvents(config, db))
+	users.Get("/:id/audit", handlers.UserAudit(config, db))
 	users.Get("/:id", handlers.FindUser(config, db))
 	users.Post("/", handlers.CreateUser(config, db))
 	users.Post("/:id", handlers.UpdateUser(config, db))
 	users.Put("/:id", handlers.PartialUpdateUser(config, db))
 	users.Delete("/:id", handlers.Delete(config, db))
+
+	audit := api.Group("/audit")
+	audit.Post("/search", handlers.SearchAudit(config, db))
+
+	webhooks := api.Group("/webhooks")
+	webhooks.Post("/", handlers.CreateWebhook(config, db))
+	webhooks.Get("/", handlers.ListWebhooks(config, db))
+	webhooks.Get("/dead-letters", handlers.WebhookDeadLetters(config, db))
+	webhooks.Get("/:id", handlers.FindWebhook(config, db))
+	webhooks.Delete("/:id", handlers.DeleteWebhook(config, db))
+	webhooks.Get("/:id/deliveries", handlers.WebhookDeliveries(config, db))
+	webhooks.Post("/:id/deliveries/:deliveryId/redeliver", handlers.RedeliverWebhook(config, db))
+
+	// the cache holds user documents and flushing it sends every read to mongo,
+	// administering it is kept to privileged tokens
+	admin := api.Group("/admin", handlers.RequirePrivileged)
+	admin.Get("/cache/stats", handlers.CacheStats(config, db))
+	admin.Get("/cache/users/:id", handlers.InspectUserCache(config, db))
+	admin.Delete("/cache/users/:id", handlers.EvictUserCache(config, db))
+	admin.Delete("/cache/:namespace", handlers.FlushCache(config, db))
+}
+
+// splitTokens splits a comma separated list of API tokens, ignoring blank items
+func splitTokens(list string) []string {
+	tokens := []string{}
+	for _, token := range strings.Split(list, ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
 }
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/database"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func testRouter(t *testing.T) (func(method, target, token string) int, func()) {
+	ctrl := gomock.NewController(t)
+	config := &configs.EnvVarConfig{APIToken: "token", PrivilegedAPITokens: "admin-token"}
+	app := Router(config, database.NewMockMongoDB(ctrl))
+	return func(method, target, token string) int {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req, -1)
+		assert.Nil(t, err)
+		return resp.StatusCode
+	}, ctrl.Finish
+}
+
+func TestFlushCacheRequiresPrivilegedToken(t *testing.T) {
+	call, finish := testRouter(t)
+	defer finish()
+
+	assert.Equal(t, http.StatusForbidden, call(http.MethodDelete, "/api/v1/admin/cache/users", "token"))
+	assert.Equal(t, http.StatusUnauthorized, call(http.MethodDelete, "/api/v1/admin/cache/users", "wrong"))
+	// the mock database has no cache, a privileged token gets past the check to the use case
+	assert.Equal(t, http.StatusNotImplemented, call(http.MethodDelete, "/api/v1/admin/cache/users", "admin-token"))
+}
+
+func TestCacheAdministrationRequiresPrivilegedToken(t *testing.T) {
+	call, finish := testRouter(t)
+	defer finish()
+
+	for _, route := range []struct{ method, target string }{
+		{http.MethodGet, "/api/v1/admin/cache/stats"},
+		{http.MethodGet, "/api/v1/admin/cache/users/123"},
+		{http.MethodDelete, "/api/v1/admin/cache/users/123"},
+	} {
+		assert.Equal(t, http.StatusForbidden, call(route.method, route.target, "token"), route.target)
+		assert.Equal(t, http.StatusNotImplemented, call(route.method, route.target, "admin-token"), route.target)
+	}
+}
@@ -0,0 +1,149 @@
+package sse
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+)
+
+const ContentType = "text/event-stream"
+
+// Event is one message of a Server-Sent Events stream
+type Event struct {
+	ID   string
+	Type string
+	Data interface{}
+}
+
+// WriteEvent writes the event frame, data is encoded as a single JSON line
+func WriteEvent(w io.Writer, event Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	frame := ""
+	if event.ID != "" {
+		frame += "id: " + event.ID + "\n"
+	}
+	if event.Type != "" {
+		frame += "event: " + event.Type + "\n"
+	}
+	frame += "data: " + string(data) + "\n\n"
+	_, err = io.WriteString(w, frame)
+	return err
+}
+
+// WriteComment writes a comment line, ignored by clients but keeps the connection alive
+func WriteComment(w io.Writer, comment string) error {
+	_, err := io.WriteString(w, ": "+strings.ReplaceAll(comment, "\n", " ")+"\n\n")
+	return err
+}
+
+// Source returns the events after lastEventID
+type Source func(lastEventID string) ([]Event, error)
+
+// Stream polls a source and writes its events until the client disconnects or the streams are closed
+type Stream struct {
+	poll      time.Duration
+	heartbeat time.Duration
+	logger    *log.Logger
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewStream(config *configs.EnvVarConfig, logger *log.Logger) *Stream {
+	poll, err := time.ParseDuration(config.SSEPollInterval)
+	if err != nil || poll <= 0 {
+		logger.Printf("Invalid sse poll interval %s, using 1s", config.SSEPollInterval)
+		poll = time.Second
+	}
+	heartbeat, err := time.ParseDuration(config.SSEHeartbeatInterval)
+	if err != nil || heartbeat <= 0 {
+		logger.Printf("Invalid sse heartbeat interval %s, using 15s", config.SSEHeartbeatInterval)
+		heartbeat = 15 * time.Second
+	}
+	return &Stream{
+		poll:      poll,
+		heartbeat: heartbeat,
+		logger:    logger,
+		closed:    make(chan struct{}),
+	}
+}
+
+// BatchSize reads the maximum number of events fetched on each poll
+func BatchSize(config *configs.EnvVarConfig, logger *log.Logger) int {
+	batchSize, err := strconv.Atoi(config.SSEBatchSize)
+	if err != nil || batchSize <= 0 {
+		logger.Printf("Invalid sse batch size %s, using 100", config.SSEBatchSize)
+		batchSize = 100
+	}
+	return batchSize
+}
+
+// Close ends every running stream, the server waits for open connections on shutdown
+func (s *Stream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
+}
+
+// Run writes the initial events and then everything the source returns, it
+// returns when a write fails, which is how a client disconnect shows up
+func (s *Stream) Run(w *bufio.Writer, lastEventID string, initial []Event, source Source) {
+	_, err := fmt.Fprintf(w, "retry: %d\n\n", s.poll.Milliseconds())
+	if err != nil {
+		return
+	}
+	lastEventID, err = s.write(w, lastEventID, initial)
+	if err != nil {
+		return
+	}
+
+	poll := time.NewTicker(s.poll)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-heartbeat.C:
+			err = WriteComment(w, "heartbeat")
+			if err == nil {
+				err = w.Flush()
+			}
+		case <-poll.C:
+			var events []Event
+			events, err = source(lastEventID)
+			if err != nil {
+				s.logger.Printf("sse source failed: %v", err)
+				continue
+			}
+			lastEventID, err = s.write(w, lastEventID, events)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Stream) write(w *bufio.Writer, lastEventID string, events []Event) (string, error) {
+	for _, event := range events {
+		err := WriteEvent(w, event)
+		if err != nil {
+			return lastEventID, err
+		}
+		if event.ID != "" {
+			lastEventID = event.ID
+		}
+	}
+	return lastEventID, w.Flush()
+}
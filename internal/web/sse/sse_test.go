@@ -0,0 +1,116 @@
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteEvent(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	err := WriteEvent(buffer, Event{ID: "1", Type: "user.created", Data: map[string]string{"name": "a\nb"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "id: 1\nevent: user.created\ndata: {\"name\":\"a\\nb\"}\n\n", buffer.String())
+
+	buffer.Reset()
+	err = WriteComment(buffer, "heartbeat")
+	assert.Nil(t, err)
+	assert.Equal(t, ": heartbeat\n\n", buffer.String())
+}
+
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+	fail   bool
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.fail {
+		return 0, errors.New("client gone")
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+func newTestStream() *Stream {
+	return NewStream(&configs.EnvVarConfig{
+		SSEPollInterval:      "10ms",
+		SSEHeartbeatInterval: "15ms",
+	}, log.New(os.Stdout, "", 0))
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStreamResumesFromLastEvent(t *testing.T) {
+	stream := newTestStream()
+	output := &syncBuffer{}
+	seen := make(chan string, 100)
+	done := make(chan struct{})
+	go func() {
+		stream.Run(bufio.NewWriter(output), "1", []Event{{ID: "2", Data: 2}}, func(lastEventID string) ([]Event, error) {
+			seen <- lastEventID
+			if lastEventID == "2" {
+				return []Event{{ID: "3", Data: 3}}, nil
+			}
+			return nil, nil
+		})
+		close(done)
+	}()
+
+	waitFor(t, func() bool {
+		return strings.Contains(output.String(), ": heartbeat") && strings.Contains(output.String(), "id: 3")
+	})
+	stream.Close()
+	<-done
+
+	assert.Equal(t, "2", <-seen)
+	assert.Equal(t, "3", <-seen)
+	assert.True(t, strings.HasPrefix(output.String(), "retry: 10\n\nid: 2\ndata: 2\n\n"))
+}
+
+func TestStreamStopsWhenClientDisconnects(t *testing.T) {
+	stream := newTestStream()
+	output := &syncBuffer{}
+	done := make(chan struct{})
+	go func() {
+		stream.Run(bufio.NewWriter(output), "", nil, func(lastEventID string) ([]Event, error) {
+			return nil, nil
+		})
+		close(done)
+	}()
+
+	waitFor(t, func() bool { return strings.Contains(output.String(), "retry") })
+	output.mutex.Lock()
+	output.fail = true
+	output.mutex.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not stop")
+	}
+}
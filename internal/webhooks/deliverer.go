@@ -0,0 +1,130 @@
+package webhooks
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+)
+
+const maxRetryBackoff = time.Hour
+
+// Deliverer performs the delivery attempts, retrying with exponential backoff
+// until the attempts are exhausted and the delivery goes to the dead-letter list
+type Deliverer struct {
+	service     services.WebhookService
+	client      *http.Client
+	backoff     time.Duration
+	maxAttempts int
+	logger      *log.Logger
+}
+
+func NewDeliverer(config *configs.EnvVarConfig, service services.WebhookService, logger *log.Logger) *Deliverer {
+	timeout, err := time.ParseDuration(config.WebhooksTimeout)
+	if err != nil {
+		logger.Printf("Invalid webhooks timeout %s, using 10s", config.WebhooksTimeout)
+		timeout = 10 * time.Second
+	}
+	backoff, err := time.ParseDuration(config.WebhooksRetryBackoff)
+	if err != nil {
+		logger.Printf("Invalid webhooks retry backoff %s, using 5s", config.WebhooksRetryBackoff)
+		backoff = 5 * time.Second
+	}
+	maxAttempts, err := strconv.Atoi(config.WebhooksMaxAttempts)
+	if err != nil || maxAttempts <= 0 {
+		logger.Printf("Invalid webhooks max attempts %s, using 8", config.WebhooksMaxAttempts)
+		maxAttempts = 8
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if config.WebhooksAllowPrivate != "true" {
+		// the addresses are checked as dialed, a proxy would dial them instead
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second, Control: dialPublic}).DialContext
+	}
+	return &Deliverer{
+		service:     service,
+		client:      &http.Client{Timeout: timeout, Transport: transport},
+		backoff:     backoff,
+		maxAttempts: maxAttempts,
+		logger:      logger,
+	}
+}
+
+// Deliver makes one attempt and stores its outcome in the delivery history
+func (d *Deliverer) Deliver(delivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	subscription, err := d.service.FindSubscription(delivery.SubscriptionID)
+	if err == services.ErrSubscriptionNotFound {
+		// the subscription was removed, there is nobody left to deliver to
+		delivery.Status = entity.DeliveryDead
+		delivery.LastError = fmt.Sprintf("subscription not found: %v", err)
+		return delivery, d.service.UpdateDelivery(delivery)
+	}
+	if err != nil {
+		// nothing was sent, the delivery is retried later without spending an attempt
+		d.logger.Printf("failed to read the subscription of webhook delivery %s: %v", delivery.ID, err)
+		delivery.Status = entity.DeliveryPending
+		delivery.LastError = fmt.Sprintf("subscription unavailable: %v", err)
+		delivery.NextAttemptAt = time.Now().UTC().Add(d.retryDelay(delivery.Attempts))
+		return delivery, d.service.UpdateDelivery(delivery)
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := d.send(subscription, delivery)
+	delivery.LastStatusCode = statusCode
+	if sendErr == nil {
+		now := time.Now().UTC()
+		delivery.Status = entity.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = time.Now().UTC().Add(d.retryDelay(delivery.Attempts))
+		delivery.Status = entity.DeliveryPending
+		if delivery.Attempts >= d.maxAttempts {
+			delivery.Status = entity.DeliveryDead
+			d.logger.Printf("webhook delivery %s is dead after %d attempts: %v", delivery.ID, delivery.Attempts, sendErr)
+		}
+	}
+	return delivery, d.service.UpdateDelivery(delivery)
+}
+
+func (d *Deliverer) send(subscription entity.WebhookSubscription, delivery entity.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(EventHeader, string(delivery.EventType))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay doubles the backoff on every attempt
+func (d *Deliverer) retryDelay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryBackoff {
+		delay = maxRetryBackoff
+	}
+	return delay
+}
@@ -0,0 +1,55 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Dispatcher is the events sink creating one pending delivery per matching subscription
+type Dispatcher struct {
+	service services.WebhookService
+}
+
+func NewDispatcher(service services.WebhookService) *Dispatcher {
+	return &Dispatcher{service: service}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhooks"
+}
+
+func (d *Dispatcher) Publish(event entity.UserEvent) error {
+	subscriptions, err := d.service.SubscriptionsFor(event.Type)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, subscription := range subscriptions {
+		_, err := d.service.AddDelivery(entity.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        string(payload),
+			Status:         entity.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		})
+		// the relay is at least once, deliveries already created for this event are kept
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// ErrPrivateTarget is returned when a webhook would reach the service's own network
+var ErrPrivateTarget = errors.New("webhook target is not a public address")
+
+// deniedNetworks are the loopback, private, shared, link-local (with the cloud
+// metadata endpoints), multicast, reserved and unspecified addresses
+var deniedNetworks = parseNetworks(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// publicIP tells whether a webhook may reach ip, IPv4-mapped addresses are checked as IPv4
+func publicIP(ip net.IP) bool {
+	for _, network := range deniedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckTarget rejects the webhook URLs naming a local host or a non public address.
+// Names are only resolved when delivering, where every address dialed is checked
+func CheckTarget(rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.ToLower(target.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// dialPublic is the dialer control refusing the connections to non public addresses,
+// it runs once the name is resolved so a name can't point a delivery at the internal network
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, host)
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/domain/entity"
+	"github.com/Shodocan/UserService/internal/services"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "whsec_test"
+
+func getConfig() *configs.EnvVarConfig {
+	return &configs.EnvVarConfig{
+		WebhooksInterval:     "10ms",
+		WebhooksBatchSize:    "10",
+		WebhooksMaxAttempts:  "3",
+		WebhooksRetryBackoff: "1s",
+		WebhooksTimeout:      "1s",
+		WebhooksAllowPrivate: "true", // the receivers listen on the loopback
+	}
+}
+
+// receiver is a local subscriber answering status and recording the verification of every call
+func receiver(t *testing.T, status int) (*httptest.Server, chan error) {
+	verified := make(chan error, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.Equal(t, "user.created", r.Header.Get(EventHeader))
+		verified <- Verify(secret, r.Header.Get(SignatureHeader), body, time.Minute)
+		w.WriteHeader(status)
+	}))
+	return server, verified
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	header := Sign(secret, time.Now(), body)
+	assert.True(t, strings.HasPrefix(header, "t="))
+	assert.Nil(t, Verify(secret, header, body, time.Minute))
+	assert.Equal(t, ErrInvalidSignature, Verify("other", header, body, time.Minute))
+	assert.Equal(t, ErrInvalidSignature, Verify(secret, header, []byte(`{"id":"2"}`), time.Minute))
+	assert.Equal(t, ErrInvalidSignature, Verify(secret, "v1=abc", body, time.Minute))
+
+	old := Sign(secret, time.Now().Add(-time.Hour), body)
+	assert.Equal(t, ErrExpiredSignature, Verify(secret, old, body, time.Minute))
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := NewSecret()
+	assert.Nil(t, err)
+	second, err := NewSecret()
+	assert.Nil(t, err)
+	assert.NotEqual(t, first, second)
+	assert.True(t, strings.HasPrefix(first, "whsec_"))
+}
+
+func TestDeliverSignedPayload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, verified := receiver(t, http.StatusOK)
+	defer server.Close()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{ID: "sub", URL: server.URL, Secret: secret}, nil)
+	serviceMock.EXPECT().UpdateDelivery(gomock.Any()).Return(nil)
+
+	delivery := entity.WebhookDelivery{ID: "1", SubscriptionID: "sub", EventType: entity.UserCreated, Payload: `{"id":"event"}`, Status: entity.DeliveryPending}
+	result, err := NewDeliverer(getConfig(), serviceMock, configs.NewLog()).Deliver(delivery)
+	assert.Nil(t, err)
+	assert.Nil(t, <-verified, "receiver must be able to verify the signature")
+	assert.Equal(t, entity.DeliveryDelivered, result.Status)
+	assert.Equal(t, 1, result.Attempts)
+	assert.Equal(t, http.StatusOK, result.LastStatusCode)
+	assert.NotNil(t, result.DeliveredAt)
+}
+
+func TestDeliverRetriesUntilDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := receiver(t, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{ID: "sub", URL: server.URL, Secret: secret}, nil).Times(3)
+	serviceMock.EXPECT().UpdateDelivery(gomock.Any()).Return(nil).Times(3)
+
+	deliverer := NewDeliverer(getConfig(), serviceMock, configs.NewLog())
+	delivery := entity.WebhookDelivery{ID: "1", SubscriptionID: "sub", EventType: entity.UserCreated, Payload: `{}`, Status: entity.DeliveryPending}
+
+	delivery, err := deliverer.Deliver(delivery)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+	assert.WithinDuration(t, time.Now().Add(time.Second), delivery.NextAttemptAt, 500*time.Millisecond)
+
+	delivery, _ = deliverer.Deliver(delivery)
+	assert.WithinDuration(t, time.Now().Add(2*time.Second), delivery.NextAttemptAt, 500*time.Millisecond)
+
+	delivery, _ = deliverer.Deliver(delivery)
+	assert.Equal(t, entity.DeliveryDead, delivery.Status, "must go to the dead-letter list after the last attempt")
+	assert.Equal(t, 3, delivery.Attempts)
+}
+
+func TestDeliverRefusesPrivateTargets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, _ := receiver(t, http.StatusOK)
+	defer server.Close()
+	// the name resolves to the loopback, only the dialed address can tell
+	target := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{ID: "sub", URL: target, Secret: secret}, nil)
+	serviceMock.EXPECT().UpdateDelivery(gomock.Any()).Return(nil)
+
+	config := getConfig()
+	config.WebhooksAllowPrivate = "false"
+	delivery := entity.WebhookDelivery{ID: "1", SubscriptionID: "sub", EventType: entity.UserCreated, Payload: `{}`, Status: entity.DeliveryPending}
+	delivery, err := NewDeliverer(config, serviceMock, configs.NewLog()).Deliver(delivery)
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DeliveryPending, delivery.Status)
+	assert.Equal(t, 0, delivery.LastStatusCode)
+	assert.Contains(t, delivery.LastError, ErrPrivateTarget.Error())
+}
+
+func TestCheckTarget(t *testing.T) {
+	for _, target := range []string{"https://example.com/hooks", "http://93.184.216.34:8080", "https://[2606:2800:220:1::]/"} {
+		assert.Nil(t, CheckTarget(target), target)
+	}
+	for _, target := range []string{
+		"http://localhost:9000", "http://api.localhost", "http://127.0.0.1", "http://10.0.0.5", "http://172.16.3.4",
+		"http://192.168.0.1", "http://169.254.169.254/latest/meta-data", "http://0.0.0.0", "http://[::1]",
+		"http://[fd00:ec2::254]", "http://[fe80::1]", "http://[::ffff:127.0.0.1]",
+	} {
+		assert.Equal(t, ErrPrivateTarget, CheckTarget(target), target)
+	}
+}
+
+func TestDeliverWithoutSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{}, services.ErrSubscriptionNotFound)
+	serviceMock.EXPECT().UpdateDelivery(gomock.Any()).Return(nil)
+
+	delivery, err := NewDeliverer(getConfig(), serviceMock, configs.NewLog()).Deliver(entity.WebhookDelivery{ID: "1", SubscriptionID: "sub"})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DeliveryDead, delivery.Status)
+	assert.Equal(t, 0, delivery.Attempts)
+}
+
+func TestDeliverRetriesUnreadableSubscription(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{}, fmt.Errorf("server selection timeout"))
+	serviceMock.EXPECT().UpdateDelivery(gomock.Any()).Return(nil)
+
+	delivery, err := NewDeliverer(getConfig(), serviceMock, configs.NewLog()).Deliver(entity.WebhookDelivery{ID: "1", SubscriptionID: "sub", Attempts: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DeliveryPending, delivery.Status, "a transient failure must not kill the delivery")
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+}
+
+func TestDispatcherFansOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	event := entity.NewUserEvent(entity.UserCreated, entity.User{ID: "123", Name: "Walisson"}, nil)
+	event.ID = "event"
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().SubscriptionsFor(entity.UserCreated).Return([]entity.WebhookSubscription{{ID: "a"}, {ID: "b"}}, nil)
+	created := []string{}
+	serviceMock.EXPECT().AddDelivery(gomock.Any()).DoAndReturn(func(delivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+		assert.Equal(t, "event", delivery.EventID)
+		assert.Equal(t, entity.DeliveryPending, delivery.Status)
+		assert.Contains(t, delivery.Payload, `"userId":"123"`)
+		created = append(created, delivery.SubscriptionID)
+		return delivery, nil
+	}).Times(2)
+
+	assert.Nil(t, NewDispatcher(serviceMock).Publish(event))
+	assert.Equal(t, []string{"a", "b"}, created)
+}
+
+func TestWorkerRunOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server, verified := receiver(t, http.StatusNoContent)
+	defer server.Close()
+
+	serviceMock := services.NewMockWebhookService(ctrl)
+	serviceMock.EXPECT().PendingDeliveries(10).Return([]entity.WebhookDelivery{
+		{ID: "1", SubscriptionID: "sub", EventType: entity.UserCreated, Payload: `{}`},
+		{ID: "2", SubscriptionID: "sub", EventType: entity.UserCreated, Payload: `{}`},
+	}, nil)
+	serviceMock.EXPECT().FindSubscription("sub").Return(entity.WebhookSubscription{ID: "sub", URL: server.URL, Secret: secret}, nil).Times(2)
+	serviceMock.EXPECT().UpdateDelivery(gomock.Any()).Return(nil).Times(2)
+
+	delivered, err := NewWorker(getConfig(), serviceMock, configs.NewLog()).RunOnce()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Nil(t, <-verified)
+	assert.Nil(t, <-verified)
+}
@@ -0,0 +1,91 @@
+package webhooks
+
+import (
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Shodocan/UserService/internal/configs"
+	"github.com/Shodocan/UserService/internal/services"
+)
+
+// Worker sends the pending deliveries in background
+type Worker struct {
+	service   services.WebhookService
+	deliverer *Deliverer
+	interval  time.Duration
+	batchSize int
+	logger    *log.Logger
+	stop      chan struct{}
+	done      sync.WaitGroup
+}
+
+func NewWorker(config *configs.EnvVarConfig, service services.WebhookService, logger *log.Logger) *Worker {
+	interval, err := time.ParseDuration(config.WebhooksInterval)
+	if err != nil {
+		logger.Printf("Invalid webhooks interval %s, using 1s", config.WebhooksInterval)
+		interval = time.Second
+	}
+	batchSize, err := strconv.Atoi(config.WebhooksBatchSize)
+	if err != nil || batchSize <= 0 {
+		logger.Printf("Invalid webhooks batch size %s, using 50", config.WebhooksBatchSize)
+		batchSize = 50
+	}
+	return &Worker{
+		service:   service,
+		deliverer: NewDeliverer(config, service, logger),
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+func (w *Worker) Start() {
+	w.stop = make(chan struct{})
+	w.done.Add(1)
+	go func() {
+		defer w.done.Done()
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				_, err := w.RunOnce()
+				if err != nil {
+					w.logger.Printf("webhooks worker failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (w *Worker) Stop() {
+	if w.stop == nil {
+		return
+	}
+	close(w.stop)
+	w.done.Wait()
+	w.stop = nil
+}
+
+// RunOnce attempts one batch of pending deliveries and returns how many were delivered
+func (w *Worker) RunOnce() (int, error) {
+	deliveries, err := w.service.PendingDeliveries(w.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, delivery := range deliveries {
+		result, err := w.deliverer.Deliver(delivery)
+		if err != nil {
+			w.logger.Printf("failed to store webhook delivery %s: %v", delivery.ID, err)
+		}
+		if result.DeliveredAt != nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
@@ -36,5 +36,6 @@ then
     exit $EXIT
 else
     mkdir -p .coverage
+    export MONGODB_TRANSACTIONS="${MONGODB_TRANSACTIONS:-false}"
     go test ./internal/... -timeout=2m -parallel=4  -covermode=atomic -coverprofile .coverage/coverage.out
 fi
\ No newline at end of file